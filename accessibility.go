@@ -0,0 +1,187 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+const (
+	// Steps the game speed and screen shake are changed in, and the slowest the game can go
+	gameSpeedStep   = 0.25
+	minGameSpeed    = 0.5
+	screenShakeStep = 0.25
+)
+
+var (
+	// Outline drawn around the ship, rockets and asteroids in high contrast mode
+	highContrastOutline = color.RGBA{0xff, 0xff, 0x00, 0xff}
+
+	// Hint text, brighter in high contrast mode
+	standardHint     = color.RGBA{0x90, 0x90, 0x90, 0xff}
+	highContrastHint = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+)
+
+// Palette is a set of colours for the things told apart by colour alone
+type Palette int
+
+const (
+	PaletteStandard Palette = 0
+
+	// For red-green colour blindness (protanopia and deuteranopia), using blues, oranges and yellows
+	PaletteRedGreen Palette = 1
+
+	// For blue-yellow colour blindness (tritanopia), using reds and blue-greens
+	PaletteBlueYellow Palette = 2
+
+	paletteCount = 3
+)
+
+func (p Palette) String() string {
+	switch p {
+	case PaletteRedGreen:
+		return tr("palette.red_green")
+	case PaletteBlueYellow:
+		return tr("palette.blue_yellow")
+	}
+	return tr("palette.standard")
+}
+
+// Colours of a palette - the end of game titles, the statistics chart, the minimap, the edge of
+// the world, the threat arrows and the tints of the other players' ships
+type PaletteColours struct {
+	won, lost                       color.RGBA
+	chartWon, chartLost             color.RGBA
+	ship, ally, enemy               color.RGBA
+	mapBackground, mapEdge, mapView color.RGBA
+	mapAsteroid, mapMini            color.RGBA
+	worldEdge                       color.RGBA
+	threatLarge, threatMini         color.RGBA
+	tints                           map[int][3]float64
+}
+
+var palettes = [paletteCount]PaletteColours{
+	PaletteStandard: {
+		won:           color.RGBA{0x4c, 0xf0, 0x4c, 0xff},
+		lost:          color.RGBA{0xe8, 0x3a, 0x2a, 0xff},
+		chartWon:      color.RGBA{0x60, 0xff, 0x60, 0xff},
+		chartLost:     color.RGBA{0x60, 0x60, 0xff, 0xff},
+		ship:          color.RGBA{0x40, 0xff, 0x40, 0xff},
+		ally:          color.RGBA{0x40, 0xa0, 0xff, 0xff},
+		enemy:         color.RGBA{0xff, 0x40, 0x40, 0xff},
+		mapBackground: color.RGBA{0x10, 0x10, 0x20, 0xc0},
+		mapEdge:       color.RGBA{0x60, 0x60, 0x80, 0xff},
+		mapView:       color.RGBA{0x80, 0x80, 0x80, 0xff},
+		mapAsteroid:   color.RGBA{0xc0, 0xa0, 0x80, 0xff},
+		mapMini:       color.RGBA{0x80, 0x70, 0x60, 0xff},
+		worldEdge:     color.RGBA{0x40, 0x40, 0x60, 0xff},
+		threatLarge:   color.RGBA{0xff, 0x40, 0x40, 0xff},
+		threatMini:    color.RGBA{0xff, 0xa0, 0x40, 0xff},
+		tints:         map[int][3]float64{2: {0.5, 1, 0.5}, 3: {0.5, 0.7, 1}, 4: {1, 0.5, 0.5}},
+	},
+	PaletteRedGreen: {
+		won:           color.RGBA{0x56, 0xb4, 0xe9, 0xff},
+		lost:          color.RGBA{0xe6, 0x9f, 0x00, 0xff},
+		chartWon:      color.RGBA{0x56, 0xb4, 0xe9, 0xff},
+		chartLost:     color.RGBA{0xe6, 0x9f, 0x00, 0xff},
+		ship:          color.RGBA{0xf0, 0xe4, 0x42, 0xff},
+		ally:          color.RGBA{0x56, 0xb4, 0xe9, 0xff},
+		enemy:         color.RGBA{0xd5, 0x5e, 0x00, 0xff},
+		mapBackground: color.RGBA{0x10, 0x10, 0x20, 0xc0},
+		mapEdge:       color.RGBA{0x70, 0x70, 0x90, 0xff},
+		mapView:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+		mapAsteroid:   color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		mapMini:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+		worldEdge:     color.RGBA{0x50, 0x50, 0x70, 0xff},
+		threatLarge:   color.RGBA{0xd5, 0x5e, 0x00, 0xff},
+		threatMini:    color.RGBA{0xf0, 0xe4, 0x42, 0xff},
+		tints:         map[int][3]float64{2: {0.35, 0.7, 0.9}, 3: {0.95, 0.9, 0.25}, 4: {0.8, 0.45, 0.65}},
+	},
+	PaletteBlueYellow: {
+		won:           color.RGBA{0x00, 0xc0, 0xc0, 0xff},
+		lost:          color.RGBA{0xe8, 0x30, 0x30, 0xff},
+		chartWon:      color.RGBA{0x00, 0xc0, 0xc0, 0xff},
+		chartLost:     color.RGBA{0xe8, 0x30, 0x30, 0xff},
+		ship:          color.RGBA{0xff, 0x80, 0xc0, 0xff},
+		ally:          color.RGBA{0x00, 0xc0, 0xc0, 0xff},
+		enemy:         color.RGBA{0xe8, 0x30, 0x30, 0xff},
+		mapBackground: color.RGBA{0x18, 0x10, 0x10, 0xc0},
+		mapEdge:       color.RGBA{0x80, 0x70, 0x70, 0xff},
+		mapView:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+		mapAsteroid:   color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		mapMini:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+		worldEdge:     color.RGBA{0x60, 0x50, 0x50, 0xff},
+		threatLarge:   color.RGBA{0xe8, 0x30, 0x30, 0xff},
+		threatMini:    color.RGBA{0xff, 0x80, 0xc0, 0xff},
+		tints:         map[int][3]float64{2: {0.3, 0.9, 0.9}, 3: {1, 0.5, 0.75}, 4: {1, 0.3, 0.3}},
+	},
+}
+
+// Switches the colours used around the game to a palette, and the hint colour for high contrast
+func useColours(p Palette, highContrast bool) {
+	c := palettes[p]
+	colourWon, colourLost = c.won, c.lost
+	chartWon, chartLost = c.chartWon, c.chartLost
+	minimapShip, minimapAlly, minimapEnemy = c.ship, c.ally, c.enemy
+	minimapBackground, minimapEdge, minimapView = c.mapBackground, c.mapEdge, c.mapView
+	minimapAsteroid, minimapMini = c.mapAsteroid, c.mapMini
+	worldEdge = c.worldEdge
+	threatLarge, threatMini = c.threatLarge, c.threatMini
+	playerTints = c.tints
+
+	colourHint = standardHint
+	if highContrast {
+		colourHint = highContrastHint
+	}
+}
+
+// Draws a sprite, with an outline around it in high contrast mode so it stands out from the background
+func (g *Game) drawSprite(screen, img *ebiten.Image, op *ebiten.DrawImageOptions) {
+
+	if g.config.HighContrast {
+		outline := *op
+		outline.ColorM.Reset()
+		outline.ColorM.Scale(0, 0, 0, 1)
+		r, gr, b := float64(highContrastOutline.R)/0xff, float64(highContrastOutline.G)/0xff, float64(highContrastOutline.B)/0xff
+		outline.ColorM.Translate(r, gr, b, 0)
+		for _, d := range [][2]float64{{-2, 0}, {2, 0}, {0, -2}, {0, 2}, {-2, -2}, {2, -2}, {-2, 2}, {2, 2}} {
+			o := outline
+			o.GeoM.Translate(d[0], d[1])
+			screen.DrawImage(img, &o)
+		}
+	}
+	screen.DrawImage(img, op)
+}
+
+// Shakes the camera, as much as the screen shake setting allows
+func (g *Game) shake(amount float64) {
+	g.camera.addShake(amount * g.config.ScreenShake)
+}
+
+// Runs fewer ticks a second during a game when it is slowed down, so menus stay responsive.
+// Network games always run at full speed, as everyone shares the host's ticks.
+func (g *Game) updateGameSpeed() {
+	tps := ticksPerSecond
+	if g.mode == ModePlay && g.host == nil && g.client == nil {
+		tps = int(float64(ticksPerSecond) * g.config.GameSpeed)
+	}
+	if ebiten.MaxTPS() != tps {
+		ebiten.SetMaxTPS(tps)
+	}
+}
+
+// Font the HUD is printed in, and how far from the right of the screen its right hand column starts
+func (g *Game) hudFace() font.Face {
+	if g.config.LargeHUD {
+		return g.fonts.large
+	}
+	return g.fonts.small
+}
+
+func (g *Game) hudRight() int {
+	if g.config.LargeHUD {
+		return 230
+	}
+	return 180
+}
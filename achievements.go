@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Time an unlock notification stays on screen
+	notificationTicks = 3 * ticksPerSecond
+)
+
+// Achievement unlocked by reaching a goal, tracked across sessions
+type Achievement struct {
+	key string
+
+	// Checks if the goal has been reached, given how the game ended (mode is ModePlay while playing)
+	reached func(g *Game, mode Mode) bool
+
+	// Optional progress towards the goal, shown on the achievements screen
+	progress func(p *Profile) (int, int)
+}
+
+// Progress towards the achievements, saved with the player data
+type Progress struct {
+	Asteroids     int      `json:"asteroids"`
+	MiniAsteroids int      `json:"miniAsteroids"`
+	Goroutines    uint64   `json:"goroutines"`
+	Strategies    []string `json:"strategies,omitempty"`
+}
+
+// Notification shown on screen
+type notification struct {
+	text  string
+	ticks int
+}
+
+var achievements = []Achievement{
+	{
+		key: "first-clear",
+		reached: func(g *Game, mode Mode) bool {
+			return mode == ModeWon
+		},
+	},
+	{
+		key: "untouchable",
+		reached: func(g *Game, mode Mode) bool {
+			return mode == ModeWon && g.level == 3 && !g.damageTaken()
+		},
+	},
+	{
+		key: "better-together",
+		reached: func(g *Game, mode Mode) bool {
+			return mode == ModeWon && len(g.players) > 1
+		},
+	},
+	{
+		key: "speed-runner",
+		reached: func(g *Game, mode Mode) bool {
+			return mode == ModeWon && g.gameType == TypeTimeAttack && ticksToMillis(g.ticks) <= levelParTimes[g.level]*1000
+		},
+	},
+	{
+		key: "survivor",
+		reached: func(g *Game, mode Mode) bool {
+			return g.gameType == TypeSurvival && g.ticks >= 120*ticksPerSecond
+		},
+	},
+	{
+		key: "champion",
+		reached: func(g *Game, mode Mode) bool {
+			return mode == ModeResult && g.localWinner()
+		},
+	},
+	{
+		key: "pebble-collector",
+		reached: func(g *Game, mode Mode) bool {
+			return g.profile.Progress.MiniAsteroids >= 100
+		},
+		progress: func(p *Profile) (int, int) {
+			return p.Progress.MiniAsteroids, 100
+		},
+	},
+	{
+		key: "rock-breaker",
+		reached: func(g *Game, mode Mode) bool {
+			return g.profile.Progress.Asteroids >= 250
+		},
+		progress: func(p *Profile) (int, int) {
+			return p.Progress.Asteroids, 250
+		},
+	},
+	{
+		key: "gopher-wrangler",
+		reached: func(g *Game, mode Mode) bool {
+			return g.profile.Progress.Goroutines >= 100000
+		},
+		progress: func(p *Profile) (int, int) {
+			return int(p.Progress.Goroutines), 100000
+		},
+	},
+	{
+		key: "concurrency-expert",
+		reached: func(g *Game, mode Mode) bool {
+			return len(g.profile.Progress.Strategies) >= strategyCount
+		},
+		progress: func(p *Profile) (int, int) {
+			return len(p.Progress.Strategies), strategyCount
+		},
+	},
+}
+
+// Name of the achievement in the chosen language
+func (a Achievement) name() string {
+	return tr("achievement." + a.key + ".name")
+}
+
+func (a Achievement) description() string {
+	return tr("achievement." + a.key + ".description")
+}
+
+// Checks if any player lost health this game
+func (g *Game) damageTaken() bool {
+	for _, p := range g.players {
+		if p.health < playerMaxHealth {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts a destroyed asteroid towards the statistics and achievements
+func (g *Game) asteroidDestroyed(mini bool) {
+	g.session.Hits++
+	g.audio.play(SoundHit)
+	if mini {
+		g.session.MiniAsteroids++
+		g.profile.Progress.MiniAsteroids++
+	} else {
+		g.session.Asteroids++
+		g.profile.Progress.Asteroids++
+	}
+	g.checkAchievements(ModePlay)
+}
+
+// Adds the finished game to the achievement progress and checks for unlocks
+func (g *Game) achievementsGameOver(mode Mode) {
+
+	progress := &g.profile.Progress
+	progress.Goroutines += uint64(generationGoroutines) + uint64(updateGoroutines)
+
+	// Only a game that was won, or a versus match played to the end, counts as finished with the strategy
+	if mode == ModeWon || mode == ModeResult {
+		strategy := g.strategy.key()
+		known := false
+		for _, s := range progress.Strategies {
+			if s == strategy {
+				known = true
+			}
+		}
+		if !known {
+			progress.Strategies = append(progress.Strategies, strategy)
+		}
+	}
+
+	g.checkAchievements(mode)
+}
+
+// Locks again any achievement saved as unlocked before its goal was reached, such as
+// concurrency-expert unlocked by versions of the game that had a single strategy
+func (p *Profile) relockUnreached() {
+	for _, a := range achievements {
+		if a.progress == nil {
+			continue
+		}
+		if have, need := a.progress(p); have < need {
+			delete(p.Achievements, a.key)
+		}
+	}
+}
+
+// Unlocks every achievement whose goal has been reached
+func (g *Game) checkAchievements(mode Mode) {
+
+	unlocked := false
+	for _, a := range achievements {
+		if _, done := g.profile.Achievements[a.key]; done || !a.reached(g, mode) {
+			continue
+		}
+		if g.profile.Achievements == nil {
+			g.profile.Achievements = make(map[string]time.Time)
+		}
+		g.profile.Achievements[a.key] = time.Now()
+		g.notify(tr("notify.achievement", a.name()))
+		fmt.Printf("%s \n", tr("console.achievement", a.name(), a.description()))
+		unlocked = true
+	}
+
+	if unlocked {
+		if err := g.profile.save(); err != nil {
+			fmt.Printf("%s \n", tr("console.error_saving_achievements", err))
+		}
+	}
+}
+
+// Shows a message on screen for a few seconds
+func (g *Game) notify(text string) {
+	g.notifications = append(g.notifications, notification{text: text, ticks: notificationTicks})
+}
+
+// Counts down the notifications on screen, removing those that have expired
+func (g *Game) updateNotifications() {
+	active := g.notifications[:0]
+	for _, n := range g.notifications {
+		n.ticks--
+		if n.ticks > 0 {
+			active = append(active, n)
+		}
+	}
+	g.notifications = active
+}
+
+// Draws the notifications on screen, newest at the bottom
+func (g *Game) drawNotifications(screen *ebiten.Image) {
+	for i, n := range g.notifications {
+		x := (screenWidth - textWidth(g.fonts.small, n.text)) / 2
+		g.print(screen, n.text, x, 150+i*20)
+	}
+}
+
+// Draws the achievements screen
+func (g *Game) drawAchievements(screen *ebiten.Image) {
+
+	unlocked := 0
+	for _, a := range achievements {
+		if _, done := g.profile.Achievements[a.key]; done {
+			unlocked++
+		}
+	}
+	drawCentredText(screen, tr("achievements.title", unlocked, len(achievements)), g.fonts.heading, 30, g.theme.text)
+
+	for i, a := range achievements {
+		y := 80 + i*45
+
+		status := tr("achievements.locked")
+		if when, done := g.profile.Achievements[a.key]; done {
+			status = tr("achievements.unlocked", when.Format("2006-01-02"))
+		} else if a.progress != nil {
+			have, need := a.progress(g.profile)
+			if have > need {
+				have = need
+			}
+			status = tr("achievements.progress", have, need)
+		}
+
+		g.print(screen, fmt.Sprintf("%s  (%s)", a.name(), status), pageLeft()+150, y)
+		g.print(screen, a.description(), pageLeft()+170, y+18)
+	}
+
+	drawCentredText(screen, g.prompt(tr("screen.back", g.config.Keys[ControlsSolo].label(ActionBack)), tr("pad.back", padLabel(ActionBack)), tr("pointer.back")), g.fonts.small, fromBottom(30), colourHint)
+}
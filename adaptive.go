@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// How often the player's performance is looked at
+	adaptiveWindowTicks = 10 * ticksPerSecond
+
+	// Bounds the difficulty is kept within, and how far each adjustment moves it
+	minAsteroidSpeed  = 0.5
+	maxAsteroidSpeed  = 2.0
+	asteroidSpeedStep = 0.25
+	minSplits         = 1
+	maxSplits         = 4
+	minSpawnSeconds   = 1
+	maxSpawnSeconds   = 8
+
+	// Performance that makes the game easier or harder
+	struggleDamage   = 20
+	struggleAccuracy = 30
+	cruiseAccuracy   = 60
+	minShotsToJudge  = 5
+)
+
+// Current difficulty, changed during play when adaptive difficulty is on
+type Difficulty struct {
+	// Multiplier for how fast the asteroids move
+	speed float64
+
+	// Mini asteroids split off when a large asteroid is hit
+	splits int
+
+	// Seconds between new asteroids in survival
+	spawnSeconds int
+
+	// Counters at the start of the window being judged
+	windowStart SessionStats
+	windowTicks int
+
+	// Adjustments made this game, with the reason for each
+	log []string
+}
+
+// Multiplier for the asteroid speed, shared with the asteroid update Go routines
+var asteroidSpeed = 1.0
+
+// Starts a new game on the difficulty of the chosen level and settings
+func (g *Game) initDifficulty() {
+	g.difficulty = Difficulty{
+		speed:        g.config.AsteroidSpeed,
+		splits:       g.config.Splits,
+		spawnSeconds: levelSpawnTimes[g.level],
+	}
+	asteroidSpeed = g.difficulty.speed
+}
+
+// Checks if the difficulty can change during the current game
+func (g *Game) adaptiveActive() bool {
+	return g.adaptive && g.gameType != TypeVersus
+}
+
+// Looks at how the player is doing every few seconds and adjusts the difficulty to match
+func (g *Game) updateDifficulty() {
+
+	if !g.adaptiveActive() {
+		return
+	}
+
+	d := &g.difficulty
+	d.windowTicks++
+	if d.windowTicks < adaptiveWindowTicks {
+		return
+	}
+
+	change, reason := g.assessPlayer()
+	d.windowStart = g.session
+	d.windowTicks = 0
+	if change == 0 {
+		return
+	}
+
+	var changes []string
+	speed := clampFloat(d.speed+float64(change)*asteroidSpeedStep, minAsteroidSpeed, maxAsteroidSpeed)
+	if speed != d.speed {
+		d.speed = speed
+		changes = append(changes, tr("difficulty.speed", speed))
+	}
+	splits := clampInt(d.splits+change, minSplits, maxSplits)
+	if splits != d.splits {
+		d.splits = splits
+		changes = append(changes, tr("difficulty.splits", splits))
+	}
+	if g.gameType == TypeSurvival {
+		spawn := clampInt(d.spawnSeconds-change, minSpawnSeconds, maxSpawnSeconds)
+		if spawn != d.spawnSeconds {
+			d.spawnSeconds = spawn
+			changes = append(changes, tr("difficulty.spawn", spawn))
+		}
+	}
+	asteroidSpeed = d.speed
+
+	if len(changes) == 0 {
+		return
+	}
+
+	direction := tr("difficulty.harder")
+	if change < 0 {
+		direction = tr("difficulty.easier")
+	}
+	entry := tr("difficulty.log", float64(g.ticks)/ticksPerSecond, direction, reason, strings.Join(changes, ", "))
+	d.log = append(d.log, entry)
+	fmt.Printf("%s \n", tr("console.difficulty", entry))
+	g.notify(tr("notify.difficulty", direction, reason))
+}
+
+// Judges the last window of play. Returns -1 to make the game easier, 1 to make it harder or 0 to leave it, with the reason.
+func (g *Game) assessPlayer() (int, string) {
+
+	start := g.difficulty.windowStart
+	shots := g.session.Shots - start.Shots
+	hits := g.session.Hits - start.Hits
+	damage := g.session.DamageTaken - start.DamageTaken
+
+	accuracy := 0.0
+	if shots > 0 {
+		accuracy = float64(hits) * 100 / float64(shots)
+	}
+	judgeAccuracy := shots >= minShotsToJudge
+
+	// Time the field is on course to be cleared in, compared with the par time
+	projected, par := g.projectedClearSeconds(), levelParTimes[g.level]
+
+	switch {
+	case damage >= struggleDamage:
+		return -1, tr("difficulty.reason.damage", damage)
+	case judgeAccuracy && accuracy < struggleAccuracy:
+		return -1, tr("difficulty.reason.low_accuracy", accuracy)
+	case projected > 2*par:
+		return -1, tr("difficulty.reason.slow", projected, par)
+	case damage == 0 && judgeAccuracy && accuracy >= cruiseAccuracy:
+		return 1, tr("difficulty.reason.high_accuracy", accuracy)
+	case damage == 0 && projected > 0 && projected < par/2:
+		return 1, tr("difficulty.reason.fast", projected, par)
+	}
+	return 0, ""
+}
+
+// Estimates how long clearing the field will take at the rate of the last window, in seconds.
+// Returns 0 where there is no field to clear.
+func (g *Game) projectedClearSeconds() int {
+
+	if g.gameType == TypeSurvival || g.gameType == TypeVersus {
+		return 0
+	}
+
+	start := g.difficulty.windowStart
+	destroyed := g.session.Asteroids - start.Asteroids + g.session.MiniAsteroids - start.MiniAsteroids
+
+	// Each large asteroid still has to be hit, then its mini asteroids
+	remaining := AsteroidsInGame*(1+g.difficulty.splits) + miniAsteroidsInGame
+	elapsed := g.ticks / ticksPerSecond
+
+	if destroyed == 0 {
+		// Nothing hit at all, so the field will not be cleared at this rate
+		return elapsed + 3*levelParTimes[g.level]
+	}
+	window := adaptiveWindowTicks / ticksPerSecond
+	return elapsed + remaining*window/destroyed
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// Draws the current difficulty under the game type during play
+func (g *Game) drawDifficultyHUD(screen *ebiten.Image) {
+	if !g.adaptiveActive() {
+		return
+	}
+	d := g.difficulty
+	g.print(screen, tr("hud.difficulty", d.speed, d.splits), fromRight(g.hudRight()), 60)
+}
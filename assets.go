@@ -0,0 +1,148 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The default images are built into the game, so it runs from any folder
+//
+//go:embed GUI
+var embeddedAssets embed.FS
+
+const (
+	// Folder inside the user config directory checked for replacement images when -assets is not given
+	userAssetsDir = "assets"
+)
+
+// Size of the placeholder drawn for each image when it can not be loaded
+var placeholderSizes = map[string][2]int{
+	"GUI/GameAssets/asteroid.png":              {120, 120},
+	"GUI/GameAssets/miniAsteroid.png":          {70, 70},
+	"GUI/GameAssets/ship.png":                  {50, 80},
+	"GUI/GameScreens/gameConcurrencyRadar.png": {250, 40},
+	"GUI/GameScreens/gameLogo.png":             {250, 250},
+	"GUI/GameScreens/gamePlayerHealth.png":     {200, 40},
+}
+
+// Place images are looked for, in order
+type assetSource struct {
+	name  string
+	files fs.FS
+}
+
+// Assets loads images from the override folder when it has them, then from the theme, and from the built in images otherwise
+type Assets struct {
+	overrides []assetSource
+	theme     *assetSource
+	builtIn   assetSource
+
+	// Counts for the summary printed once everything has loaded
+	loaded       map[string]int
+	placeholders int
+}
+
+// Sets up the image sources. An empty dir uses the assets folder in the user config directory, if there is one.
+func newAssets(dir string) *Assets {
+	a := &Assets{loaded: make(map[string]int)}
+
+	if dir == "" {
+		if config, err := os.UserConfigDir(); err == nil {
+			if info, err := os.Stat(filepath.Join(config, profileDir, userAssetsDir)); err == nil && info.IsDir() {
+				dir = filepath.Join(config, profileDir, userAssetsDir)
+			}
+		}
+	}
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			fmt.Printf("%s \n", tr("console.assets_unusable", dir))
+		} else {
+			fmt.Printf("%s \n", tr("console.assets_override", dir))
+			a.overrides = append(a.overrides, assetSource{name: dir, files: os.DirFS(dir)})
+		}
+	}
+
+	a.builtIn = assetSource{name: tr("console.built_in"), files: embeddedAssets}
+	return a
+}
+
+// Returns the places images are looked for, in order
+func (a *Assets) sources() []assetSource {
+	sources := append([]assetSource{}, a.overrides...)
+	if a.theme != nil {
+		sources = append(sources, *a.theme)
+	}
+	return append(sources, a.builtIn)
+}
+
+// Loads an image by its path, e.g. "GUI/GameAssets/ship.png", from the first source that has a good copy.
+// A placeholder is returned if no source does, so the game can always start.
+func (a *Assets) image(path, description string) *ebiten.Image {
+
+	for _, s := range a.sources() {
+		f, err := s.files.Open(path)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				fmt.Printf("%s \n", tr("console.assets_open_failed", path, description, s.name, err))
+			}
+			continue
+		}
+
+		img, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			fmt.Printf("%s \n", tr("console.assets_invalid", path, description, s.name, err))
+			continue
+		}
+
+		a.loaded[s.name]++
+		return ebiten.NewImageFromImage(img)
+	}
+
+	fmt.Printf("%s \n", tr("console.assets_placeholder", path, description))
+	a.placeholders++
+	size, ok := placeholderSizes[path]
+	if !ok {
+		size = [2]int{64, 64}
+	}
+	return placeholder(size[0], size[1])
+}
+
+// Prints how many images came from each source, then starts counting again for the next load
+func (a *Assets) summary() {
+	for _, s := range a.sources() {
+		if n := a.loaded[s.name]; n > 0 {
+			fmt.Printf("%s \n", tr("console.assets_loaded", n, s.name))
+		}
+	}
+	if a.placeholders > 0 {
+		fmt.Printf("%s \n", tr("console.assets_missing", a.placeholders))
+	}
+	a.loaded = make(map[string]int)
+	a.placeholders = 0
+}
+
+// Magenta and black checks with a border, hard to miss when an image has not loaded
+func placeholder(w, h int) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	magenta := color.RGBA{0xff, 0x00, 0xff, 0xff}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x == 0 || y == 0 || x == w-1 || y == h-1 || (x/10+y/10)%2 == 0 {
+				img.Set(x, y, magenta)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	// Shortest gap between two plays of the same sound, so sounds played every tick do not pile up
+	soundCooldownTicks = 6
+
+	// How much each volume key press changes the volume
+	volumeStep = 0.1
+)
+
+// AudioBackend plays the sounds and music
+type AudioBackend interface {
+	PlaySound(s Sound, volume float64)
+	PlayMusic(m Music, volume float64)
+	SetMusicVolume(volume float64)
+
+	// Replaces the sounds and music with new 16 bit stereo samples
+	Load(sounds [soundCount][]byte, music [musicCount][]byte)
+}
+
+// Plays through ebiten's audio package
+type ebitenAudio struct {
+	context *audio.Context
+	sounds  [soundCount][]byte
+	music   [musicCount][]byte
+	player  *audio.Player
+}
+
+// Creates the audio context, the sounds are loaded with the theme
+func newEbitenAudio() *ebitenAudio {
+	return &ebitenAudio{context: audio.NewContext(sampleRate)}
+}
+
+func (a *ebitenAudio) Load(sounds [soundCount][]byte, music [musicCount][]byte) {
+	a.sounds, a.music = sounds, music
+}
+
+func (a *ebitenAudio) PlaySound(s Sound, volume float64) {
+	p := a.context.NewPlayerFromBytes(a.sounds[s])
+	p.SetVolume(volume)
+	p.Play()
+}
+
+func (a *ebitenAudio) PlayMusic(m Music, volume float64) {
+	if a.player != nil {
+		a.player.Close()
+		a.player = nil
+	}
+	if m == MusicNone {
+		return
+	}
+
+	loop := audio.NewInfiniteLoop(bytes.NewReader(a.music[m]), int64(len(a.music[m])))
+	p, err := a.context.NewPlayer(loop)
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.error_playing_music", err))
+		return
+	}
+	p.SetVolume(volume)
+	p.Play()
+	a.player = p
+}
+
+func (a *ebitenAudio) SetMusicVolume(volume float64) {
+	if a.player != nil {
+		a.player.SetVolume(volume)
+	}
+}
+
+// Plays nothing, used when the game is run with -noaudio and wherever no sound device is wanted
+type nullAudio struct{}
+
+func (nullAudio) PlaySound(s Sound, volume float64)                        {}
+func (nullAudio) PlayMusic(m Music, volume float64)                        {}
+func (nullAudio) SetMusicVolume(volume float64)                            {}
+func (nullAudio) Load(sounds [soundCount][]byte, music [musicCount][]byte) {}
+
+// Audio chooses what to play and how loud
+type Audio struct {
+	backend AudioBackend
+
+	volume      float64
+	sfxVolume   float64
+	musicVolume float64
+	muted       bool
+
+	music    Music
+	lastPlay [soundCount]int
+	tick     int
+
+	// Generated sounds and music, made once and used wherever a theme has none of its own
+	defaultSounds [soundCount][]byte
+	defaultMusic  [musicCount][]byte
+}
+
+// Creates the audio for the game, playing nothing when disabled
+func newAudio(enabled bool) *Audio {
+	a := &Audio{backend: nullAudio{}, volume: 0.8, sfxVolume: 1, musicVolume: 0.5}
+	if enabled {
+		a.backend = newEbitenAudio()
+	}
+	for i := range a.lastPlay {
+		a.lastPlay[i] = -soundCooldownTicks
+	}
+	for s := Sound(0); s < soundCount; s++ {
+		a.defaultSounds[s] = pcm(generateSound(s))
+	}
+	for m := MusicNone + 1; m < musicCount; m++ {
+		a.defaultMusic[m] = pcm(generateMusic(m))
+	}
+	a.backend.Load(a.defaultSounds, a.defaultMusic)
+	return a
+}
+
+// Loads the theme's sounds and music, restarting the music playing
+func (a *Audio) useTheme(t *Theme) {
+	if a == nil {
+		return
+	}
+
+	sounds, music := a.defaultSounds, a.defaultMusic
+	for s := Sound(0); s < soundCount; s++ {
+		if data := t.sound(soundNames[s]); data != nil {
+			sounds[s] = data
+		}
+	}
+	for m := MusicNone + 1; m < musicCount; m++ {
+		if data := t.sound(musicNames[m]); data != nil {
+			music[m] = data
+		}
+	}
+	a.backend.Load(sounds, music)
+
+	playing := a.music
+	a.music = MusicNone
+	a.playMusic(playing)
+}
+
+// Plays a sound effect, unless it has just been played
+func (a *Audio) play(s Sound) {
+	if a == nil || a.muted || a.tick-a.lastPlay[s] < soundCooldownTicks {
+		return
+	}
+	a.lastPlay[s] = a.tick
+	a.backend.PlaySound(s, a.volume*a.sfxVolume)
+}
+
+// Switches the background music, carrying on if it is already playing
+func (a *Audio) playMusic(m Music) {
+	if a == nil || m == a.music {
+		return
+	}
+	a.music = m
+	a.backend.PlayMusic(m, a.currentMusicVolume())
+}
+
+func (a *Audio) currentMusicVolume() float64 {
+	if a.muted {
+		return 0
+	}
+	return a.volume * a.musicVolume
+}
+
+// Sets the master, sound effect and music volumes, each from 0 to 1
+func (a *Audio) setVolumes(volume, sfx, music float64, muted bool) {
+	if a == nil {
+		return
+	}
+	a.volume, a.sfxVolume, a.musicVolume, a.muted = volume, sfx, music, muted
+	a.backend.SetMusicVolume(a.currentMusicVolume())
+}
+
+// Returns the music for each screen
+func (m Mode) music() Music {
+	switch m {
+	case ModePlay, ModePause:
+		return MusicPlay
+	case ModeOver, ModeWon, ModeResult:
+		return MusicResults
+	}
+	return MusicMenu
+}
+
+// Plays the music for the current screen, a blip on changing screen, and handles the volume keys
+func (g *Game) updateAudio() {
+
+	if g.audio == nil {
+		return
+	}
+	g.audio.tick++
+
+	if g.mode != g.lastMode {
+		if g.mode.music() == MusicMenu && g.lastMode.music() == MusicMenu {
+			g.audio.play(SoundMenu)
+		}
+		g.lastMode = g.mode
+	}
+	g.audio.playMusic(g.mode.music())
+
+	// The volume keys and buttons change the saved settings, like the audio settings screen
+	c := g.config
+	if g.actionPressed(ControlsSolo, ActionMute) {
+		c.Muted = !c.Muted
+		g.configChanged()
+		if c.Muted {
+			g.notify(tr("notify.muted"))
+		} else {
+			g.notify(tr("notify.unmuted"))
+		}
+	}
+	if g.actionPressed(ControlsSolo, ActionVolumeDown) {
+		c.Volume = clampFloat(c.Volume-volumeStep, 0, 1)
+		g.configChanged()
+		g.notify(tr("notify.volume", c.Volume*100))
+	}
+	if g.actionPressed(ControlsSolo, ActionVolumeUp) {
+		c.Volume = clampFloat(c.Volume+volumeStep, 0, 1)
+		g.configChanged()
+		g.notify(tr("notify.volume", c.Volume*100))
+	}
+}
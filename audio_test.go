@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"testing/fstest"
+)
+
+// Records what the game asks to be played, while playing nothing like nullAudio
+type recordingAudio struct {
+	nullAudio
+
+	sounds      []Sound
+	soundVolume float64
+	music       []Music
+	musicVolume float64
+	loaded      [soundCount][]byte
+	loadedMusic [musicCount][]byte
+}
+
+func (r *recordingAudio) PlaySound(s Sound, volume float64) {
+	r.sounds = append(r.sounds, s)
+	r.soundVolume = volume
+}
+
+func (r *recordingAudio) PlayMusic(m Music, volume float64) {
+	r.music = append(r.music, m)
+	r.musicVolume = volume
+}
+
+func (r *recordingAudio) SetMusicVolume(volume float64) {
+	r.musicVolume = volume
+}
+
+func (r *recordingAudio) Load(sounds [soundCount][]byte, music [musicCount][]byte) {
+	r.loaded, r.loadedMusic = sounds, music
+}
+
+// Audio without a sound device, recording what it plays
+func newTestAudio() (*Audio, *recordingAudio) {
+	a := newAudio(false)
+	r := &recordingAudio{}
+	a.backend = r
+	return a, r
+}
+
+// A 16 bit stereo WAV file at the game's sample rate holding the samples
+func wavFile(samples []byte) []byte {
+	var b bytes.Buffer
+	w := func(v interface{}) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	w(uint32(36 + len(samples)))
+	b.WriteString("WAVEfmt ")
+	w(uint32(16))
+	w(uint16(1))
+	w(uint16(2))
+	w(uint32(sampleRate))
+	w(uint32(sampleRate * 4))
+	w(uint16(4))
+	w(uint16(16))
+	b.WriteString("data")
+	w(uint32(len(samples)))
+	b.Write(samples)
+	return b.Bytes()
+}
+
+func TestNullAudioPlaysNothing(t *testing.T) {
+	a := newAudio(false)
+	if _, ok := a.backend.(nullAudio); !ok {
+		t.Fatalf("backend = %T, want nullAudio", a.backend)
+	}
+	a.play(SoundFire)
+	a.playMusic(MusicPlay)
+	a.setVolumes(0.5, 0.5, 0.5, true)
+	a.useTheme(classicTheme())
+}
+
+func TestSoundCooldown(t *testing.T) {
+	a, r := newTestAudio()
+
+	a.play(SoundFire)
+	a.play(SoundFire)
+	a.play(SoundHit)
+	if len(r.sounds) != 2 || r.sounds[0] != SoundFire || r.sounds[1] != SoundHit {
+		t.Fatalf("played %v, want fire once then hit", r.sounds)
+	}
+
+	a.tick += soundCooldownTicks - 1
+	a.play(SoundFire)
+	if len(r.sounds) != 2 {
+		t.Fatalf("fire played again before the cooldown")
+	}
+	a.tick++
+	a.play(SoundFire)
+	if len(r.sounds) != 3 {
+		t.Fatalf("fire not played after the cooldown")
+	}
+}
+
+func TestMuteAndVolumes(t *testing.T) {
+	a, r := newTestAudio()
+	a.playMusic(MusicPlay)
+
+	a.setVolumes(0.5, 0.4, 0.6, false)
+	if r.musicVolume != 0.5*0.6 {
+		t.Errorf("music volume = %v, want %v", r.musicVolume, 0.5*0.6)
+	}
+	a.play(SoundFire)
+	if len(r.sounds) != 1 || r.soundVolume != 0.5*0.4 {
+		t.Errorf("sound played %v at %v, want once at %v", r.sounds, r.soundVolume, 0.5*0.4)
+	}
+
+	a.setVolumes(0.5, 0.4, 0.6, true)
+	if r.musicVolume != 0 {
+		t.Errorf("muted music volume = %v, want 0", r.musicVolume)
+	}
+	a.tick += soundCooldownTicks
+	a.play(SoundHit)
+	if len(r.sounds) != 1 {
+		t.Errorf("sound played while muted")
+	}
+
+	// Music carries on rather than restarting when the same music is asked for again
+	a.playMusic(MusicPlay)
+	if len(r.music) != 1 {
+		t.Errorf("music restarted: %v", r.music)
+	}
+}
+
+func TestThemeSoundsFallBackToDefaults(t *testing.T) {
+	a, r := newTestAudio()
+	a.playMusic(MusicMenu)
+
+	hit := []byte{1, 0, 2, 0, 3, 0, 4, 0}
+	theme := &Theme{
+		key:      "test",
+		manifest: ThemeManifest{Sounds: map[string]string{"hit": "hit.wav", "fire": "missing.wav"}},
+		files:    fstest.MapFS{"hit.wav": {Data: wavFile(hit)}},
+	}
+	a.useTheme(theme)
+
+	if !bytes.Equal(r.loaded[SoundHit], hit) {
+		t.Errorf("hit sound = %v, want the theme's %v", r.loaded[SoundHit], hit)
+	}
+	for s := Sound(0); s < soundCount; s++ {
+		if s != SoundHit && !bytes.Equal(r.loaded[s], a.defaultSounds[s]) {
+			t.Errorf("%s sound is not the default", soundNames[s])
+		}
+	}
+	for m := MusicNone + 1; m < musicCount; m++ {
+		if !bytes.Equal(r.loadedMusic[m], a.defaultMusic[m]) {
+			t.Errorf("%s is not the default", musicNames[m])
+		}
+	}
+
+	// The music playing is restarted with the new sounds
+	if len(r.music) != 2 || r.music[1] != MusicMenu {
+		t.Errorf("music played %v, want the menu music restarted", r.music)
+	}
+
+	a.useTheme(classicTheme())
+	if !bytes.Equal(r.loaded[SoundHit], a.defaultSounds[SoundHit]) {
+		t.Errorf("hit sound kept after going back to the default theme")
+	}
+}
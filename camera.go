@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// How quickly the camera catches up with the ship and the zoom with its target, per tick
+	cameraFollow = 0.12
+	cameraZoomIn = 0.08
+
+	// Zoom limits and how far each key press zooms
+	minZoom  = 0.5
+	maxZoom  = 1.5
+	zoomStep = 0.25
+
+	// Space kept around the ships when zooming out to fit them all in
+	cameraMargin = 300.0
+
+	// Shake strength for each kind of impact, in pixels, and how fast it dies away
+	shakeExplosion = 4.0
+	shakeShipHit   = 3.0
+	shakeRocketHit = 8.0
+	shakeDecay     = 0.85
+)
+
+// Camera looks at a part of the world, following the ships
+type Camera struct {
+	// Centre of the view in world coordinates
+	x, y float64
+
+	zoom float64
+
+	// Zoom chosen with the zoom keys, the camera zooms out further when needed to fit every ship in
+	userZoom float64
+
+	shake          float64
+	shakeX, shakeY float64
+}
+
+// Centres the camera on the ships straight away, used when a game starts
+func (g *Game) resetCamera() {
+	if g.camera.userZoom == 0 {
+		g.camera.userZoom = 1
+	}
+	x, y, zoom := g.cameraTarget()
+	g.camera.x, g.camera.y, g.camera.zoom = x, y, zoom
+	g.camera.shake = 0
+	g.camera.clamp()
+}
+
+// Players the camera follows - this machine's ships, or every ship when spectating
+func (g *Game) followedPlayers() []*Player {
+	var followed []*Player
+	for _, p := range g.players {
+		if !p.alive() {
+			continue
+		}
+		switch {
+		case g.client != nil && g.client.spectator:
+			followed = append(followed, p)
+		case g.client != nil:
+			if p.id == g.client.PlayerID() {
+				followed = append(followed, p)
+			}
+		case !p.remote:
+			followed = append(followed, p)
+		}
+	}
+	return followed
+}
+
+// Returns where the camera should look and how far it should zoom to keep the followed ships in view
+func (g *Game) cameraTarget() (float64, float64, float64) {
+
+	followed := g.followedPlayers()
+	if len(followed) == 0 {
+		return g.camera.x, g.camera.y, g.camera.userZoom
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range followed {
+		minX, maxX = math.Min(minX, p.shipXPos), math.Max(maxX, p.shipXPos+shipWidth)
+		minY, maxY = math.Min(minY, p.shipYPos), math.Max(maxY, p.shipYPos+shipHeight)
+	}
+
+	zoom := g.camera.userZoom
+	if len(followed) > 1 {
+		fit := math.Min(float64(screenWidth)/(maxX-minX+cameraMargin), float64(screenHeight)/(maxY-minY+cameraMargin))
+		zoom = clampFloat(math.Min(zoom, fit), minZoom, maxZoom)
+	}
+	return (minX + maxX) / 2, (minY + maxY) / 2, zoom
+}
+
+// Moves the camera towards the ships, zooming and shaking, once a tick
+func (g *Game) updateCamera() {
+
+	if g.mode != ModePlay {
+		return
+	}
+	if g.camera.zoom == 0 {
+		g.resetCamera()
+	}
+
+	if g.actionPressed(ControlsSolo, ActionZoomIn) || g.zoomPressed(1) {
+		g.camera.userZoom = clampFloat(g.camera.userZoom+zoomStep, minZoom, maxZoom)
+	}
+	if g.actionPressed(ControlsSolo, ActionZoomOut) || g.zoomPressed(-1) {
+		g.camera.userZoom = clampFloat(g.camera.userZoom-zoomStep, minZoom, maxZoom)
+	}
+
+	c := &g.camera
+	x, y, zoom := g.cameraTarget()
+	c.x += (x - c.x) * cameraFollow
+	c.y += (y - c.y) * cameraFollow
+	c.zoom += (zoom - c.zoom) * cameraZoomIn
+	c.clamp()
+
+	c.shakeX = (rand.Float64()*2 - 1) * c.shake
+	c.shakeY = (rand.Float64()*2 - 1) * c.shake
+	c.shake *= shakeDecay
+	if c.shake < 0.1 {
+		c.shake = 0
+	}
+}
+
+// Keeps the view inside the world, centring it where the world is smaller than the view
+func (c *Camera) clamp() {
+	halfW, halfH := float64(screenWidth)/2/c.zoom, float64(screenHeight)/2/c.zoom
+
+	if 2*halfW >= worldWidth {
+		c.x = worldWidth / 2
+	} else {
+		c.x = clampFloat(c.x, halfW, worldWidth-halfW)
+	}
+	if 2*halfH >= worldHeight {
+		c.y = worldHeight / 2
+	} else {
+		c.y = clampFloat(c.y, halfH, worldHeight-halfH)
+	}
+}
+
+// Shakes the camera, a stronger shake replacing a weaker one
+func (c *Camera) addShake(amount float64) {
+	if amount > c.shake {
+		c.shake = amount
+	}
+}
+
+// Transform from world coordinates to screen coordinates
+func (c *Camera) geoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	m.Translate(-c.x, -c.y)
+	m.Scale(c.zoom, c.zoom)
+	m.Translate(float64(screenWidth)/2+c.shakeX, float64(screenHeight)/2+c.shakeY)
+	return m
+}
+
+// Converts a point in the world to a point on screen
+func (c *Camera) worldToScreen(x, y float64) (float64, float64) {
+	m := c.geoM()
+	return m.Apply(x, y)
+}
+
+// Converts a point on screen to a point in the world
+func (c *Camera) screenToWorld(x, y float64) (float64, float64) {
+	m := c.geoM()
+	m.Invert()
+	return m.Apply(x, y)
+}
+
+// Edge of the world, from the palette in use (see useColours)
+var worldEdge = palettes[PaletteStandard].worldEdge
+
+// Draws the edge of the world
+func (g *Game) drawWorldBounds(screen *ebiten.Image) {
+	x0, y0 := g.camera.worldToScreen(0, 0)
+	x1, y1 := g.camera.worldToScreen(worldWidth, worldHeight)
+	drawOutline(screen, x0, y0, x1-x0, y1-y0, worldEdge)
+}
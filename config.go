@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// File the settings are kept in, next to the player data
+	configFile = "config.json"
+)
+
+// Sizes the window can be scaled to
+var windowScales = []float64{1, 1.25, 1.5, 2}
+
+// Settings saved between sessions, changed on the settings screens
+type Config struct {
+	// Video
+	Scale      float64 `json:"scale"`
+	Fullscreen bool    `json:"fullscreen"`
+	VSync      bool    `json:"vsync"`
+
+	// Height of the logical screen, and whether it keeps to 4:3 or fills the window
+	Resolution int        `json:"resolution"`
+	Aspect     AspectMode `json:"aspect"`
+
+	// Audio, each volume from 0 to 1
+	Volume      float64 `json:"volume"`
+	SoundVolume float64 `json:"soundVolume"`
+	MusicVolume float64 `json:"musicVolume"`
+	Muted       bool    `json:"muted"`
+
+	// Controls - the keys for each action, for one player and for players one and two
+	Keys [controlSetCount]Bindings `json:"keys"`
+
+	// Gameplay - the difficulty each game starts on
+	AsteroidSpeed float64       `json:"asteroidSpeed"`
+	Splits        int           `json:"splits"`
+	Adaptive      bool          `json:"adaptive"`
+	AsteroidStyle AsteroidStyle `json:"asteroidStyle"`
+
+	// Accessibility - colours and contrast, how fast the game runs, firing whenever the rocket is
+	// ready or with a press to start and stop, flashing and shaking, and the size of the HUD text
+	Palette        Palette `json:"palette"`
+	HighContrast   bool    `json:"highContrast"`
+	GameSpeed      float64 `json:"gameSpeed"`
+	AutoFire       bool    `json:"autoFire"`
+	ToggleFire     bool    `json:"toggleFire"`
+	ReduceFlashing bool    `json:"reduceFlashing"`
+	ScreenShake    float64 `json:"screenShake"`
+	LargeHUD       bool    `json:"largeHud"`
+
+	// Educational - how the asteroids are updated and how much of that the radar shows
+	Strategy    string      `json:"strategy"`
+	RadarDetail RadarDetail `json:"radarDetail"`
+}
+
+// Returns the settings the game starts with before any are changed
+func defaultConfig() *Config {
+	return &Config{
+		Scale:         1,
+		VSync:         true,
+		Resolution:    baseHeight,
+		Volume:        0.8,
+		SoundVolume:   1,
+		MusicVolume:   0.5,
+		Keys:          defaultBindings(),
+		AsteroidSpeed: 1,
+		Splits:        2,
+		GameSpeed:     1,
+		ScreenShake:   1,
+		Strategy:      StrategyGoroutinePerAsteroid.key(),
+		RadarDetail:   RadarFull,
+	}
+}
+
+// Returns the path of the settings file
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileDir, configFile), nil
+}
+
+// Loads the settings, using the defaults for any that are missing or out of range
+func loadConfig() *Config {
+	c := defaultConfig()
+
+	path, err := configPath()
+	if err != nil {
+		return c
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("%s \n", tr("console.error_loading_settings", err))
+		}
+		return c
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		fmt.Printf("%s \n", tr("console.error_reading_settings", err))
+		return defaultConfig()
+	}
+	c.check()
+	return c
+}
+
+// Puts back the default for any setting that has been edited to a value the game can not use
+func (c *Config) check() {
+	d := defaultConfig()
+
+	if windowScaleIndex(c.Scale) < 0 {
+		c.Scale = d.Scale
+	}
+	if screenResolutionIndex(c.Resolution) < 0 {
+		c.Resolution = d.Resolution
+	}
+	if c.Aspect < 0 || c.Aspect >= aspectModeCount {
+		c.Aspect = d.Aspect
+	}
+	c.Volume = clampFloat(c.Volume, 0, 1)
+	c.SoundVolume = clampFloat(c.SoundVolume, 0, 1)
+	c.MusicVolume = clampFloat(c.MusicVolume, 0, 1)
+	if c.AsteroidSpeed < minAsteroidSpeed || c.AsteroidSpeed > maxAsteroidSpeed {
+		c.AsteroidSpeed = d.AsteroidSpeed
+	}
+	if c.Splits < minSplits || c.Splits > maxSplits {
+		c.Splits = d.Splits
+	}
+	if c.AsteroidStyle < 0 || c.AsteroidStyle >= asteroidStyleCount {
+		c.AsteroidStyle = d.AsteroidStyle
+	}
+	if c.Palette < 0 || c.Palette >= paletteCount {
+		c.Palette = d.Palette
+	}
+	if c.GameSpeed < minGameSpeed || c.GameSpeed > 1 {
+		c.GameSpeed = d.GameSpeed
+	}
+	c.ScreenShake = clampFloat(c.ScreenShake, 0, 1)
+	c.Strategy = strategyFromKey(c.Strategy).key()
+	if c.RadarDetail < 0 || c.RadarDetail >= radarDetailCount {
+		c.RadarDetail = d.RadarDetail
+	}
+
+	// An action with no keys could never be used, the co-op sets only need the ship's actions
+	for set := range c.Keys {
+		for a := Action(0); a < actionCount; a++ {
+			if len(c.Keys[set][a]) == 0 && (set == int(ControlsSolo) || a.ship()) {
+				c.Keys[set][a] = d.Keys[set][a]
+			}
+		}
+	}
+}
+
+// Saves the settings to the user config directory
+func (c *Config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Index of a scale in the window scales, -1 if it is not one of them
+func windowScaleIndex(scale float64) int {
+	for i, s := range windowScales {
+		if s == scale {
+			return i
+		}
+	}
+	return -1
+}
+
+// Puts the settings into effect, when the game starts and whenever one is changed
+func (g *Game) applyConfig() {
+	c := g.config
+
+	// A window resized by hand keeps its size until the scale is changed
+	if c.Scale != g.windowScale {
+		ebiten.SetWindowSize(int(float64(baseWidth)*c.Scale), int(float64(baseHeight)*c.Scale))
+		g.windowScale = c.Scale
+	}
+	if ebiten.IsFullscreen() != c.Fullscreen {
+		ebiten.SetFullscreen(c.Fullscreen)
+	}
+	if ebiten.IsVsyncEnabled() != c.VSync {
+		ebiten.SetVsyncEnabled(c.VSync)
+	}
+
+	g.audio.setVolumes(c.Volume, c.SoundVolume, c.MusicVolume, c.Muted)
+
+	useColours(c.Palette, c.HighContrast)
+	g.particles.dim = c.ReduceFlashing
+
+	g.adaptive = c.Adaptive
+	g.asteroidStyle = c.AsteroidStyle
+	g.strategy = strategyFromKey(c.Strategy)
+}
+
+// Applies the settings straight away and saves them
+func (g *Game) configChanged() {
+	g.applyConfig()
+	if err := g.config.save(); err != nil {
+		fmt.Printf("%s \n", tr("console.error_saving_settings", err))
+	}
+}
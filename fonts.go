@@ -0,0 +1,172 @@
+package main
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font with Japanese, Chinese and other characters the Go fonts do not have
+//
+//go:embed fonts/mplus-1p-regular.ttf
+var fallbackFont []byte
+
+// Font sizes in pixels
+const (
+	titleSize   = 44
+	headingSize = 26
+	bodySize    = 20
+	smallSize   = 14
+
+	// Small text made larger for the HUD, an accessibility option
+	largeSize = 18
+)
+
+// Fonts used for the menus and screens, made from the Go fonts built into the game
+type Fonts struct {
+	title   font.Face
+	heading font.Face
+	body    font.Face
+	small   font.Face
+	large   font.Face
+}
+
+// Loads the fonts. They are part of the game so this only fails if they are damaged.
+func loadFonts() Fonts {
+	regular := parseFont(goregular.TTF)
+	bold := parseFont(gobold.TTF)
+	fallback := parseFont(fallbackFont)
+
+	return Fonts{
+		title:   newFallbackFace(titleSize, bold, fallback),
+		heading: newFallbackFace(headingSize, bold, fallback),
+		body:    newFallbackFace(bodySize, regular, fallback),
+		small:   newFallbackFace(smallSize, regular, fallback),
+		large:   newFallbackFace(largeSize, regular, fallback),
+	}
+}
+
+func parseFont(data []byte) *opentype.Font {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		log.Fatal(tr("console.error_loading_font", err))
+	}
+	return f
+}
+
+// Face that draws each character with the first font that has it, so text in any language can be shown
+type fallbackFace struct {
+	fonts []*opentype.Font
+	faces []font.Face
+
+	// Face chosen for each character drawn so far
+	chosen map[rune]font.Face
+	buf    sfnt.Buffer
+}
+
+func newFallbackFace(size float64, fonts ...*opentype.Font) *fallbackFace {
+	f := &fallbackFace{fonts: fonts, chosen: make(map[rune]font.Face)}
+	for _, ft := range fonts {
+		face, err := opentype.NewFace(ft, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			log.Fatal(tr("console.error_loading_font", err))
+		}
+		f.faces = append(f.faces, face)
+	}
+	return f
+}
+
+// Returns the face to draw a character with, the first font's if no font has it
+func (f *fallbackFace) face(r rune) font.Face {
+	if face, ok := f.chosen[r]; ok {
+		return face
+	}
+	face := f.faces[0]
+	if i := f.fontFor(r); i >= 0 {
+		face = f.faces[i]
+	}
+	f.chosen[r] = face
+	return face
+}
+
+// Index of the first font with a glyph for the character, -1 if none has one
+func (f *fallbackFace) fontFor(r rune) int {
+	for i, ft := range f.fonts {
+		if index, err := ft.GlyphIndex(&f.buf, r); err == nil && index != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.face(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.face(r).GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if face := f.face(r0); face == f.face(r1) {
+		return face.Kern(r0, r1)
+	}
+	return 0
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+// Returns the characters in s that none of the fonts can draw
+func (fs Fonts) missingGlyphs(s string) []rune {
+	var missing []rune
+	for _, r := range s {
+		if r != ' ' && r != '\n' && fs.body.(*fallbackFace).fontFor(r) < 0 {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Width of a line of text in pixels
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// Draws text with its top left corner at x, y
+func drawText(screen *ebiten.Image, s string, face font.Face, x, y int, c color.Color) {
+	text.Draw(screen, s, face, x, y+face.Metrics().Ascent.Ceil(), c)
+}
+
+// Draws text centred across the screen with its top at y
+func drawCentredText(screen *ebiten.Image, s string, face font.Face, y int, c color.Color) {
+	drawText(screen, s, face, (screenWidth-textWidth(face, s))/2, y, c)
+}
+
+// Draws a line of HUD or screen text in the small font, or the large one when the HUD text is
+// made larger, white, with its top left corner at x, y
+func (g *Game) print(screen *ebiten.Image, s string, x, y int) {
+	drawText(screen, s, g.hudFace(), x, y, color.White)
+}
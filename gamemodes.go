@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Game Type include -> [Classic, Time Attack, Survival, Versus]
+type GameType int
+
+const (
+	TypeClassic    GameType = 0
+	TypeTimeAttack GameType = 1
+	TypeSurvival   GameType = 2
+	TypeVersus     GameType = 3
+
+	gameTypeCount = 4
+
+	// Ebiten runs Update 60 times a second
+	ticksPerSecond = 60
+
+	// Points for shooting down asteroids
+	asteroidPoints     = 100
+	miniAsteroidPoints = 50
+)
+
+// Asteroids generated for each level
+var levelAsteroids = map[int]int{1: 5, 2: 10, 3: 20}
+
+// Time attack par times for each level, in seconds
+var levelParTimes = map[int]int{1: 30, 2: 60, 3: 120}
+
+// Seconds between new asteroids in survival for each level
+var levelSpawnTimes = map[int]int{1: 4, 2: 3, 3: 2}
+
+func (t GameType) String() string {
+	switch t {
+	case TypeTimeAttack:
+		return tr("gametype.time_attack")
+	case TypeSurvival:
+		return tr("gametype.survival")
+	case TypeVersus:
+		return tr("gametype.versus")
+	}
+	return tr("gametype.classic")
+}
+
+// One line explaining how the game type is played and won
+func (t GameType) description() string {
+	switch t {
+	case TypeTimeAttack:
+		return tr("gametype.time_attack.description")
+	case TypeSurvival:
+		return tr("gametype.survival.description")
+	case TypeVersus:
+		return tr("gametype.versus.description")
+	}
+	return tr("gametype.classic.description")
+}
+
+// Reports if score a beats score b - time attack is won by the lowest time
+func (t GameType) better(a, b int) bool {
+	if t == TypeTimeAttack {
+		return a < b
+	}
+	return a > b
+}
+
+// Formats a score for display - timed modes are scored in milliseconds
+func (t GameType) formatScore(score int) string {
+	if t == TypeClassic {
+		return fmt.Sprintf("%d", score)
+	}
+	return fmt.Sprintf("%.1fs", float64(score)/1000)
+}
+
+// Converts game ticks into milliseconds
+func ticksToMillis(ticks int) int {
+	return ticks * 1000 / ticksPerSecond
+}
+
+// Per tick rules for the chosen game type
+func (g *Game) updateGameType() {
+	g.ticks++
+
+	if g.gameType == TypeSurvival && g.ticks%(g.difficulty.spawnSeconds*ticksPerSecond) == 0 {
+		spawnAsteroid(g)
+	}
+	g.updateWaves()
+}
+
+// Checks if the player has won the game
+func (g *Game) fieldCleared() bool {
+	return g.gameType != TypeSurvival && g.gameType != TypeVersus && AsteroidsInGame == 0 && miniAsteroidsInGame == 0 && !g.wavesPending()
+}
+
+// Ends the game, recording the score, the statistics and any unlocked achievements
+func (g *Game) finishGame(mode Mode) {
+	g.mode = mode
+	g.newBest = false
+
+	g.recordScore(mode)
+	g.recordSession(mode)
+	g.achievementsGameOver(mode)
+
+	if err := g.profile.save(); err != nil {
+		fmt.Printf("%s \n", tr("console.error_saving_player_data", err))
+	}
+}
+
+// Records the score of a finished game with the high scores
+func (g *Game) recordScore(mode Mode) {
+
+	// Versus is played for the match, not for high scores
+	if g.gameType == TypeVersus {
+		return
+	}
+
+	switch g.gameType {
+	case TypeClassic:
+		g.lastScore = g.totalScore()
+	case TypeTimeAttack:
+		// Only a cleared field counts as a time
+		if mode != ModeWon {
+			g.lastScore = -1
+			return
+		}
+		g.lastScore = ticksToMillis(g.ticks)
+	case TypeSurvival:
+		g.lastScore = ticksToMillis(g.ticks)
+	}
+
+	g.newBest = g.profile.recordHighScore(g.gameType, g.level, len(g.players), g.lastScore)
+}
+
+// Draws the score or timer for the chosen game type
+func (g *Game) drawGameTypeHUD(screen *ebiten.Image) {
+
+	var status string
+	seconds := float64(g.ticks) / ticksPerSecond
+
+	switch g.gameType {
+	case TypeClassic:
+		status = tr("hud.score", g.totalScore())
+	case TypeTimeAttack:
+		status = tr("hud.time", seconds, levelParTimes[g.level])
+	case TypeSurvival:
+		status = tr("hud.survived", seconds)
+	case TypeVersus:
+		g.print(screen, g.gameType.String(), fromRight(g.hudRight()), 20)
+		g.drawVersusHUD(screen)
+		return
+	}
+
+	g.print(screen, g.gameType.String(), fromRight(g.hudRight()), 20)
+	g.print(screen, status, fromRight(g.hudRight()), 40)
+}
+
+// Draws the result of the last game on the game over and won screens
+func (g *Game) drawGameResult(screen *ebiten.Image) {
+
+	result := tr("result.level", g.gameType, g.level)
+	if g.lastScore >= 0 {
+		result += "   " + tr("result.score", g.gameType.formatScore(g.lastScore))
+	}
+	if g.gameType == TypeTimeAttack && g.lastScore >= 0 {
+		if g.lastScore <= levelParTimes[g.level]*1000 {
+			result += "   " + tr("result.under_par")
+		} else {
+			result += "   " + tr("result.over_par")
+		}
+	}
+	drawCentredText(screen, result, g.fonts.body, 200, g.theme.text)
+
+	if g.newBest {
+		drawCentredText(screen, tr("result.new_best"), g.fonts.heading, 240, g.theme.highlight)
+	}
+
+	if len(g.players) > 1 {
+		scores := ""
+		for _, p := range g.players {
+			scores += tr("result.player_score", p.id, p.score) + "   "
+		}
+		drawCentredText(screen, scores, g.fonts.body, 290, g.theme.text)
+	}
+}
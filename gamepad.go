@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// How far a stick must be pushed before it counts, so a worn stick does not drift the ship
+	stickDeadZone = 0.25
+)
+
+// Gamepad buttons for each action, in the standard layout that ebiten maps every known pad to
+var padButtons = [actionCount][]ebiten.StandardGamepadButton{
+	ActionLeft:    {ebiten.StandardGamepadButtonLeftLeft},
+	ActionRight:   {ebiten.StandardGamepadButtonLeftRight},
+	ActionUp:      {ebiten.StandardGamepadButtonLeftTop},
+	ActionDown:    {ebiten.StandardGamepadButtonLeftBottom},
+	ActionFire:    {ebiten.StandardGamepadButtonRightBottom, ebiten.StandardGamepadButtonFrontBottomRight},
+	ActionPause:   {ebiten.StandardGamepadButtonCenterRight},
+	ActionConfirm: {ebiten.StandardGamepadButtonRightBottom},
+	ActionBack:    {ebiten.StandardGamepadButtonRightRight},
+
+	ActionZoomIn:     {ebiten.StandardGamepadButtonFrontTopRight},
+	ActionZoomOut:    {ebiten.StandardGamepadButtonFrontTopLeft},
+	ActionQuit:       {ebiten.StandardGamepadButtonCenterLeft},
+	ActionMute:       {ebiten.StandardGamepadButtonRightTop},
+	ActionVolumeDown: {ebiten.StandardGamepadButtonLeftStick},
+	ActionVolumeUp:   {ebiten.StandardGamepadButtonRightStick},
+}
+
+// Name of a gamepad button as shown on screen, using the labels of the common pads
+func padButtonName(b ebiten.StandardGamepadButton) string {
+	switch b {
+	case ebiten.StandardGamepadButtonRightBottom:
+		return "A"
+	case ebiten.StandardGamepadButtonRightRight:
+		return "B"
+	case ebiten.StandardGamepadButtonRightLeft:
+		return "X"
+	case ebiten.StandardGamepadButtonRightTop:
+		return "Y"
+	case ebiten.StandardGamepadButtonFrontTopLeft:
+		return "LB"
+	case ebiten.StandardGamepadButtonFrontTopRight:
+		return "RB"
+	case ebiten.StandardGamepadButtonFrontBottomLeft:
+		return "LT"
+	case ebiten.StandardGamepadButtonFrontBottomRight:
+		return "RT"
+	case ebiten.StandardGamepadButtonLeftStick:
+		return "L3"
+	case ebiten.StandardGamepadButtonRightStick:
+		return "R3"
+	case ebiten.StandardGamepadButtonCenterLeft:
+		return tr("pad.button_back")
+	case ebiten.StandardGamepadButtonCenterRight:
+		return tr("pad.button_start")
+	}
+	return tr("pad.dpad")
+}
+
+// Names of the gamepad buttons for an action, as shown on screen
+func padLabel(a Action) string {
+	var names []string
+	for _, b := range padButtons[a] {
+		names = append(names, padButtonName(b))
+	}
+	return strings.Join(names, " / ")
+}
+
+// Gamepads plugged in, and the ones the two players in co-op and versus fly with
+type Gamepads struct {
+	// In the order they were plugged in
+	connected []ebiten.GamepadID
+
+	// Pad for players one and two, when they have one
+	players  [2]ebiten.GamepadID
+	assigned [2]bool
+}
+
+// Index of the co-op player flying with a set of controls, or -1 for a single player
+func (s ControlSet) player() int {
+	switch s {
+	case ControlsPlayerOne:
+		return 0
+	case ControlsPlayerTwo:
+		return 1
+	}
+	return -1
+}
+
+// Picks up gamepads being plugged in and pulled out, and notices which device was used last
+func (g *Game) updateGamepads() {
+
+	pads := &g.pads
+	for _, id := range inpututil.JustConnectedGamepadIDs() {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			fmt.Printf("%s \n", tr("console.gamepad_no_layout", ebiten.GamepadName(id)))
+			continue
+		}
+		pads.connected = append(pads.connected, id)
+
+		// Give the pad to the first player without one
+		for i := range pads.players {
+			if !pads.assigned[i] {
+				pads.players[i], pads.assigned[i] = id, true
+				break
+			}
+		}
+		g.notify(tr("notify.gamepad_connected", ebiten.GamepadName(id)))
+	}
+
+	var kept []ebiten.GamepadID
+	for _, id := range pads.connected {
+		if !inpututil.IsGamepadJustDisconnected(id) {
+			kept = append(kept, id)
+			continue
+		}
+		for i := range pads.players {
+			if pads.assigned[i] && pads.players[i] == id {
+				pads.assigned[i] = false
+			}
+		}
+		g.notify(tr("notify.gamepad_disconnected"))
+
+		// Stop the game rather than leave a ship flying itself
+		if g.mode == ModePlay && g.client == nil {
+			g.mode = ModePause
+		}
+	}
+	pads.connected = kept
+	if len(pads.connected) == 0 && g.device == DeviceGamepad {
+		g.device = DeviceKeyboard
+	}
+
+	for _, id := range pads.connected {
+		for b := ebiten.StandardGamepadButton(0); b <= ebiten.StandardGamepadButtonMax; b++ {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, b) {
+				g.device = DeviceGamepad
+				return
+			}
+		}
+		if x, y := stick(id); x != 0 || y != 0 {
+			g.device = DeviceGamepad
+		}
+	}
+}
+
+// Gamepads used with a set of controls. A single player can use any of them.
+func (g *Game) padsFor(set ControlSet) []ebiten.GamepadID {
+	if i := set.player(); i >= 0 {
+		if g.pads.assigned[i] {
+			return []ebiten.GamepadID{g.pads.players[i]}
+		}
+		return nil
+	}
+	return g.pads.connected
+}
+
+// Checks if a gamepad button for the action is held down
+func (g *Game) padHeld(set ControlSet, a Action) bool {
+	for _, id := range g.padsFor(set) {
+		for _, b := range padButtons[a] {
+			if ebiten.IsStandardGamepadButtonPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks if a gamepad button for the action went down this tick
+func (g *Game) padPressed(set ControlSet, a Action) bool {
+	for _, id := range g.padsFor(set) {
+		for _, b := range padButtons[a] {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Position of a pad's left stick from -1 to 1, or 0 inside the dead zone
+func stick(id ebiten.GamepadID) (float64, float64) {
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	if math.Hypot(x, y) < stickDeadZone {
+		return 0, 0
+	}
+	return x, y
+}
+
+// Position of the stick pushed furthest on the gamepads for a set of controls
+func (g *Game) stickFor(set ControlSet) (float64, float64) {
+	var bestX, bestY float64
+	for _, id := range g.padsFor(set) {
+		if x, y := stick(id); math.Hypot(x, y) > math.Hypot(bestX, bestY) {
+			bestX, bestY = x, y
+		}
+	}
+	return bestX, bestY
+}
+
+// Changes which connected pad a co-op player uses, a pad taken from the other player swapping over
+func (g *Game) changePad(set ControlSet, by int) {
+
+	i := set.player()
+	pads := &g.pads
+	if i < 0 || len(pads.connected) == 0 {
+		return
+	}
+
+	// Step through the connected pads, then no pad at all
+	current := len(pads.connected)
+	for n, id := range pads.connected {
+		if pads.assigned[i] && pads.players[i] == id {
+			current = n
+		}
+	}
+	next := (current + len(pads.connected) + 1 + by) % (len(pads.connected) + 1)
+	if next == len(pads.connected) {
+		pads.assigned[i] = false
+		return
+	}
+
+	id := pads.connected[next]
+	other := 1 - i
+	if pads.assigned[other] && pads.players[other] == id {
+		pads.players[other], pads.assigned[other] = pads.players[i], pads.assigned[i]
+	}
+	pads.players[i], pads.assigned[i] = id, true
+}
+
+// Name of the pad a co-op player uses, as shown on the controls screen
+func (g *Game) padName(set ControlSet) string {
+	i := set.player()
+	if i < 0 || !g.pads.assigned[i] {
+		return tr("controls.no_gamepad")
+	}
+	return ebiten.GamepadName(g.pads.players[i])
+}
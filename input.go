@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something a player does, bound to one or more keys
+type Action int
+
+const (
+	// Flying the ship
+	ActionLeft  Action = 0
+	ActionRight Action = 1
+	ActionUp    Action = 2
+	ActionDown  Action = 3
+	ActionFire  Action = 4
+
+	// Shared by everyone, bound with the one player controls
+	ActionPause   Action = 5
+	ActionConfirm Action = 6
+	ActionBack    Action = 7
+
+	// Zooming the camera, leaving a network game and the sound
+	ActionZoomIn     Action = 8
+	ActionZoomOut    Action = 9
+	ActionQuit       Action = 10
+	ActionMute       Action = 11
+	ActionVolumeDown Action = 12
+	ActionVolumeUp   Action = 13
+
+	actionCount = 14
+)
+
+// Name saved in the settings file, which must not change
+func (a Action) key() string {
+	return [actionCount]string{"left", "right", "up", "down", "fire", "pause", "confirm", "back",
+		"zoomIn", "zoomOut", "quit", "mute", "volumeDown", "volumeUp"}[a]
+}
+
+func (a Action) String() string {
+	return tr("action." + a.key())
+}
+
+// Checks if the action flies a ship, rather than being shared by everyone
+func (a Action) ship() bool {
+	return a < ActionPause
+}
+
+// Set of controls a local player flies with
+type ControlSet int
+
+const (
+	// A single player, whose set also holds the shared actions
+	ControlsSolo ControlSet = 0
+
+	// The two players in co-op and versus, sharing the keyboard
+	ControlsPlayerOne ControlSet = 1
+	ControlsPlayerTwo ControlSet = 2
+
+	controlSetCount = 3
+)
+
+func (s ControlSet) String() string {
+	switch s {
+	case ControlsPlayerOne:
+		return tr("controls.player_one")
+	case ControlsPlayerTwo:
+		return tr("controls.player_two")
+	}
+	return tr("controls.solo")
+}
+
+// Keys bound to each action, saved by name so they do not depend on ebiten's key numbers
+type Bindings [actionCount][]ebiten.Key
+
+// Returns the keys the game starts with for each set of controls
+func defaultBindings() [controlSetCount]Bindings {
+	k := func(keys ...ebiten.Key) []ebiten.Key { return keys }
+	return [controlSetCount]Bindings{
+		// A single player can fly with either the arrow keys or WASD
+		ControlsSolo: {
+			ActionLeft:    k(ebiten.KeyLeft, ebiten.KeyA),
+			ActionRight:   k(ebiten.KeyRight, ebiten.KeyD),
+			ActionUp:      k(ebiten.KeyUp, ebiten.KeyW),
+			ActionDown:    k(ebiten.KeyDown, ebiten.KeyS),
+			ActionFire:    k(ebiten.KeySpace),
+			ActionPause:   k(ebiten.KeyP),
+			ActionConfirm: k(ebiten.KeyEnter),
+			ActionBack:    k(ebiten.KeyEscape),
+
+			ActionZoomIn:     k(ebiten.KeyEqual),
+			ActionZoomOut:    k(ebiten.KeyMinus),
+			ActionQuit:       k(ebiten.KeyQ),
+			ActionMute:       k(ebiten.KeyF1),
+			ActionVolumeDown: k(ebiten.KeyF2),
+			ActionVolumeUp:   k(ebiten.KeyF3),
+		},
+		// Player one keeps WASD and player two takes the arrow keys
+		ControlsPlayerOne: {
+			ActionLeft:  k(ebiten.KeyA),
+			ActionRight: k(ebiten.KeyD),
+			ActionUp:    k(ebiten.KeyW),
+			ActionDown:  k(ebiten.KeyS),
+			ActionFire:  k(ebiten.KeySpace),
+		},
+		ControlsPlayerTwo: {
+			ActionLeft:  k(ebiten.KeyLeft),
+			ActionRight: k(ebiten.KeyRight),
+			ActionUp:    k(ebiten.KeyUp),
+			ActionDown:  k(ebiten.KeyDown),
+			ActionFire:  k(ebiten.KeyEnter),
+		},
+	}
+}
+
+func (b Bindings) MarshalJSON() ([]byte, error) {
+	names := make(map[string][]string)
+	for a := Action(0); a < actionCount; a++ {
+		for _, k := range b[a] {
+			names[a.key()] = append(names[a.key()], k.String())
+		}
+	}
+	return json.Marshal(names)
+}
+
+// Reads the keys for the actions in the file, keeping the current keys for the rest
+func (b *Bindings) UnmarshalJSON(data []byte) error {
+	var names map[string][]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	for a := Action(0); a < actionCount; a++ {
+		list, ok := names[a.key()]
+		if !ok {
+			continue
+		}
+		var keys []ebiten.Key
+		for _, name := range list {
+			k, ok := keyFromName(name)
+			if !ok {
+				fmt.Printf("%s \n", tr("console.unknown_key", name, a.key()))
+				continue
+			}
+			keys = append(keys, k)
+		}
+		b[a] = keys
+	}
+	return nil
+}
+
+// Returns the key with a name given by ebiten, e.g. "Space" or "ArrowUp"
+func keyFromName(name string) (ebiten.Key, bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if k.String() == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// Binds a key to an action, taking it from any other action in the set. An action left
+// with no keys is given the keys the changed action had, so the two swap.
+func (b *Bindings) bind(a Action, key ebiten.Key) {
+	old := b[a]
+	for other := range b {
+		if Action(other) == a || !containsKey(b[other], key) {
+			continue
+		}
+		b[other] = removeKey(b[other], key)
+		if len(b[other]) == 0 {
+			b[other] = removeKey(old, key)
+		}
+	}
+	b[a] = []ebiten.Key{key}
+}
+
+func containsKey(keys []ebiten.Key, key ebiten.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func removeKey(keys []ebiten.Key, key ebiten.Key) []ebiten.Key {
+	var kept []ebiten.Key
+	for _, k := range keys {
+		if k != key {
+			kept = append(kept, k)
+		}
+	}
+	return kept
+}
+
+// Names of the keys bound to an action, as shown on screen
+func (b *Bindings) label(a Action) string {
+	var names []string
+	for _, k := range b[a] {
+		names = append(names, keyName(k))
+	}
+	return strings.Join(names, " / ")
+}
+
+// Names of the keys that move the ship, grouped so each way of flying reads as one, e.g. "W A S D"
+func (b *Bindings) moveLabel() string {
+	var groups []string
+	for i := 0; ; i++ {
+		var names []string
+		for _, a := range []Action{ActionUp, ActionLeft, ActionDown, ActionRight} {
+			if i < len(b[a]) {
+				names = append(names, keyName(b[a][i]))
+			}
+		}
+		if len(names) == 0 {
+			return strings.Join(groups, " / ")
+		}
+		groups = append(groups, strings.Join(names, " "))
+	}
+}
+
+// Device the player last used, so the prompts on screen show its keys, buttons or what to tap
+type Device int
+
+const (
+	DeviceKeyboard Device = 0
+	DeviceGamepad  Device = 1
+	DevicePointer  Device = 2
+)
+
+// Returns the prompt for the device used last
+func (g *Game) prompt(keyboard, gamepad, pointer string) string {
+	switch g.device {
+	case DeviceGamepad:
+		return gamepad
+	case DevicePointer:
+		return pointer
+	}
+	return keyboard
+}
+
+// InputSource tells the game which keys are held down, read from the keyboard or played from a script
+type InputSource interface {
+	// Moves on to the next tick
+	Update()
+
+	// Checks if a key is held down
+	Pressed(key ebiten.Key) bool
+
+	// Checks if a key went down this tick
+	JustPressed(key ebiten.Key) bool
+}
+
+// Reads the real keyboard
+type keyboardInput struct{}
+
+func (keyboardInput) Update() {}
+
+func (keyboardInput) Pressed(key ebiten.Key) bool {
+	return ebiten.IsKeyPressed(key)
+}
+
+func (keyboardInput) JustPressed(key ebiten.Key) bool {
+	return inpututil.IsKeyJustPressed(key)
+}
+
+// Plays back the keys held on each tick, to drive the game from tests and demos
+type ScriptedInput struct {
+	ticks [][]ebiten.Key
+	tick  int
+}
+
+// Creates a script from the keys held on each tick, starting on the first tick the game updates
+func NewScriptedInput(ticks [][]ebiten.Key) *ScriptedInput {
+	return &ScriptedInput{ticks: ticks, tick: -1}
+}
+
+// Reads a script with a line for each step, the number of ticks followed by the keys held for them,
+// e.g. "30 ArrowLeft Space". Blank lines and lines starting with # are skipped.
+func ReadInputScript(r io.Reader) (*ScriptedInput, error) {
+
+	var ticks [][]ebiten.Key
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		count, err := strconv.Atoi(fields[0])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("line %d: %q is not a number of ticks", line, fields[0])
+		}
+		var keys []ebiten.Key
+		for _, name := range fields[1:] {
+			k, ok := keyFromName(name)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown key %q", line, name)
+			}
+			keys = append(keys, k)
+		}
+		for i := 0; i < count; i++ {
+			ticks = append(ticks, keys)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewScriptedInput(ticks), nil
+}
+
+func (s *ScriptedInput) Update() {
+	s.tick++
+}
+
+// Checks if the script has no more ticks to play
+func (s *ScriptedInput) Done() bool {
+	return s.tick >= len(s.ticks)
+}
+
+func (s *ScriptedInput) heldOn(tick int, key ebiten.Key) bool {
+	return tick >= 0 && tick < len(s.ticks) && containsKey(s.ticks[tick], key)
+}
+
+func (s *ScriptedInput) Pressed(key ebiten.Key) bool {
+	return s.heldOn(s.tick, key)
+}
+
+func (s *ScriptedInput) JustPressed(key ebiten.Key) bool {
+	return s.heldOn(s.tick, key) && !s.heldOn(s.tick-1, key)
+}
+
+// Moves the input on a tick, going back to the keyboard when a script has finished
+func (g *Game) updateInput() {
+	g.input.Update()
+	if s, ok := g.input.(*ScriptedInput); ok && s.Done() {
+		fmt.Println(tr("console.script_finished"))
+		g.input = keyboardInput{}
+	}
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if g.input.JustPressed(k) {
+			g.device = DeviceKeyboard
+			break
+		}
+	}
+	g.updateGamepads()
+	g.updatePointer()
+}
+
+// Checks if any key or gamepad button for the action is held down
+func (g *Game) actionHeld(set ControlSet, a Action) bool {
+	for _, k := range g.config.Keys[set][a] {
+		if g.input.Pressed(k) {
+			return true
+		}
+	}
+	return g.padHeld(set, a)
+}
+
+// Checks if a key or gamepad button for the action went down this tick
+func (g *Game) actionPressed(set ControlSet, a Action) bool {
+	for _, k := range g.config.Keys[set][a] {
+		if g.input.JustPressed(k) {
+			return true
+		}
+	}
+	return g.padPressed(set, a)
+}
+
+// Reads which of a set of controls are held down this tick, and where the gamepad stick is
+func (g *Game) controlState(set ControlSet) InputState {
+	// Fire can be pressed once to keep firing and again to stop, rather than held down
+	fire := g.actionHeld(set, ActionFire)
+	if g.config.ToggleFire {
+		if g.actionPressed(set, ActionFire) {
+			g.fireToggled[set] = !g.fireToggled[set]
+		}
+		fire = g.fireToggled[set]
+	}
+
+	in := InputState{
+		Left:  g.actionHeld(set, ActionLeft),
+		Right: g.actionHeld(set, ActionRight),
+		Up:    g.actionHeld(set, ActionUp),
+		Down:  g.actionHeld(set, ActionDown),
+		Fire:  fire || g.config.AutoFire,
+	}
+	in.StickX, in.StickY = g.stickFor(set)
+	g.addPointerState(set, &in)
+	return in
+}
+
+// Progress of changing a key on the controls screen
+type Remap struct {
+	// Set of controls shown, and the action waiting for a key
+	set     ControlSet
+	action  Action
+	waiting bool
+}
+
+// Binds the next key pressed to the action being changed, Escape or the gamepad's back button
+// leaving it as it was
+func (g *Game) updateRemap() {
+
+	if g.input.JustPressed(ebiten.KeyEscape) || g.padPressed(ControlsSolo, ActionBack) {
+		g.remap.waiting = false
+		return
+	}
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if g.input.JustPressed(k) {
+			g.config.Keys[g.remap.set].bind(g.remap.action, k)
+			g.remap.waiting = false
+			g.configChanged()
+			return
+		}
+	}
+}
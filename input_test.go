@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestReadInputScript(t *testing.T) {
+	script := `
+# fly left while firing, then wait
+2 ArrowLeft Space
+
+1
+`
+	s, err := ReadInputScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatalf("reading script: %v", err)
+	}
+
+	want := [][]ebiten.Key{{ebiten.KeyLeft, ebiten.KeySpace}, {ebiten.KeyLeft, ebiten.KeySpace}, nil}
+	if len(s.ticks) != len(want) {
+		t.Fatalf("ticks = %v, want %v", s.ticks, want)
+	}
+	for i := range want {
+		if len(s.ticks[i]) != len(want[i]) {
+			t.Fatalf("tick %d = %v, want %v", i, s.ticks[i], want[i])
+		}
+		for j := range want[i] {
+			if s.ticks[i][j] != want[i][j] {
+				t.Fatalf("tick %d = %v, want %v", i, s.ticks[i], want[i])
+			}
+		}
+	}
+}
+
+func TestReadInputScriptErrors(t *testing.T) {
+	for _, script := range []string{
+		"ArrowLeft",
+		"0 Space",
+		"-3 Space",
+		"2 Space\n3 NotAKey",
+	} {
+		if _, err := ReadInputScript(strings.NewReader(script)); err == nil {
+			t.Errorf("script %q was read without an error", script)
+		}
+	}
+}
+
+func TestScriptedInputEdges(t *testing.T) {
+	s := NewScriptedInput([][]ebiten.Key{
+		{ebiten.KeySpace},
+		{ebiten.KeySpace},
+		nil,
+		{ebiten.KeySpace},
+	})
+
+	// Pressed and just pressed for Space on each tick
+	want := [][2]bool{{true, true}, {true, false}, {false, false}, {true, true}}
+	for tick, w := range want {
+		s.Update()
+		if s.Done() {
+			t.Fatalf("script done at tick %d", tick)
+		}
+		if got := s.Pressed(ebiten.KeySpace); got != w[0] {
+			t.Errorf("tick %d: pressed = %v, want %v", tick, got, w[0])
+		}
+		if got := s.JustPressed(ebiten.KeySpace); got != w[1] {
+			t.Errorf("tick %d: just pressed = %v, want %v", tick, got, w[1])
+		}
+		if s.Pressed(ebiten.KeyEnter) {
+			t.Errorf("tick %d: Enter pressed", tick)
+		}
+	}
+
+	s.Update()
+	if !s.Done() || s.Pressed(ebiten.KeySpace) {
+		t.Fatalf("script still playing after its last tick")
+	}
+}
+
+func TestBindSwapsKeys(t *testing.T) {
+	b := defaultBindings()[ControlsPlayerOne]
+
+	// Fire takes W from up, which is left with no keys and so takes fire's old key
+	b.bind(ActionFire, ebiten.KeyW)
+	if len(b[ActionFire]) != 1 || b[ActionFire][0] != ebiten.KeyW {
+		t.Errorf("fire = %v, want W", b[ActionFire])
+	}
+	if len(b[ActionUp]) != 1 || b[ActionUp][0] != ebiten.KeySpace {
+		t.Errorf("up = %v, want Space", b[ActionUp])
+	}
+
+	// A key no other action uses is simply bound
+	b.bind(ActionLeft, ebiten.KeyJ)
+	if len(b[ActionLeft]) != 1 || b[ActionLeft][0] != ebiten.KeyJ {
+		t.Errorf("left = %v, want J", b[ActionLeft])
+	}
+
+	solo := defaultBindings()[ControlsSolo]
+
+	// An action keeping another key only loses the one taken
+	solo.bind(ActionFire, ebiten.KeyA)
+	if len(solo[ActionLeft]) != 1 || solo[ActionLeft][0] != ebiten.KeyLeft {
+		t.Errorf("left = %v, want only the left arrow", solo[ActionLeft])
+	}
+}
+
+func TestBindingsJSON(t *testing.T) {
+	b := defaultBindings()[ControlsSolo]
+	b.bind(ActionFire, ebiten.KeyZ)
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("saving: %v", err)
+	}
+	if !strings.Contains(string(data), `"fire":["Z"]`) || !strings.Contains(string(data), `"up":["ArrowUp","W"]`) {
+		t.Fatalf("saved as %s, want keys by name", data)
+	}
+
+	var loaded Bindings
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if loaded.label(ActionFire) != b.label(ActionFire) || loaded.moveLabel() != b.moveLabel() {
+		t.Errorf("loaded %v, want %v", loaded, b)
+	}
+
+	// Actions missing from the file keep their keys, and unknown keys are skipped
+	loaded = defaultBindings()[ControlsSolo]
+	if err := json.Unmarshal([]byte(`{"fire":["X","NotAKey"]}`), &loaded); err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if len(loaded[ActionFire]) != 1 || loaded[ActionFire][0] != ebiten.KeyX {
+		t.Errorf("fire = %v, want X", loaded[ActionFire])
+	}
+	defaults := defaultBindings()[ControlsSolo]
+	if loaded.label(ActionPause) != defaults.label(ActionPause) {
+		t.Errorf("pause = %v, want the default", loaded[ActionPause])
+	}
+}
+
+// A game played from a script, saving its settings to a folder removed after the test
+func newScriptedGame(t *testing.T, ticks ...[]ebiten.Key) *Game {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("AppData", t.TempDir())
+	g := &Game{config: defaultConfig(), input: NewScriptedInput(ticks)}
+	g.audio, _ = newTestAudio()
+	return g
+}
+
+func TestScriptedGameUsesBindings(t *testing.T) {
+	g := newScriptedGame(t,
+		[]ebiten.Key{ebiten.KeyJ, ebiten.KeyZ},
+		[]ebiten.Key{ebiten.KeyJ, ebiten.KeyZ},
+		nil,
+		[]ebiten.Key{ebiten.KeyA, ebiten.KeySpace},
+	)
+	g.config.Keys[ControlsSolo].bind(ActionLeft, ebiten.KeyJ)
+	g.config.Keys[ControlsSolo].bind(ActionFire, ebiten.KeyZ)
+
+	// Left held, fire pressed and flying left and firing, on each tick
+	want := [][4]bool{
+		{true, true, true, true},
+		{true, false, true, true},
+		{false, false, false, false},
+		// The keys the actions had before do nothing now
+		{false, false, false, false},
+	}
+	for tick, w := range want {
+		g.updateInput()
+		got := [4]bool{g.actionHeld(ControlsSolo, ActionLeft), g.actionPressed(ControlsSolo, ActionFire)}
+		in := g.controlState(ControlsSolo)
+		got[2], got[3] = in.Left, in.Fire
+		if got != w {
+			t.Errorf("tick %d: left held, fire pressed, flying left, firing = %v, want %v", tick, got, w)
+		}
+	}
+}
+
+func TestScriptedGameToggleAndAutoFire(t *testing.T) {
+	fire := []ebiten.Key{ebiten.KeyZ}
+	g := newScriptedGame(t, fire, fire, nil, nil, fire, nil)
+	g.config.Keys[ControlsSolo].bind(ActionFire, ebiten.KeyZ)
+	g.config.ToggleFire = true
+
+	// Pressing fire starts firing until it is pressed again
+	want := []bool{true, true, true, true, false, false}
+	for tick, w := range want {
+		g.updateInput()
+		if got := g.controlState(ControlsSolo).Fire; got != w {
+			t.Errorf("tick %d: firing = %v, want %v", tick, got, w)
+		}
+	}
+
+	g = newScriptedGame(t, nil)
+	g.config.AutoFire = true
+	g.updateInput()
+	if !g.controlState(ControlsSolo).Fire {
+		t.Errorf("not firing with auto fire on")
+	}
+}
+
+func TestScriptedGameRemapsKeys(t *testing.T) {
+	g := newScriptedGame(t, []ebiten.Key{ebiten.KeyK})
+	g.remap = Remap{set: ControlsPlayerOne, action: ActionFire, waiting: true}
+	g.updateInput()
+	g.updateRemap()
+
+	keys := g.config.Keys[ControlsPlayerOne][ActionFire]
+	if g.remap.waiting || len(keys) != 1 || keys[0] != ebiten.KeyK {
+		t.Fatalf("fire = %v, waiting %v, want K and no longer waiting", keys, g.remap.waiting)
+	}
+	// The new key is saved with the settings
+	saved := loadConfig()
+	if keys := saved.Keys[ControlsPlayerOne][ActionFire]; len(keys) != 1 || keys[0] != ebiten.KeyK {
+		t.Errorf("saved fire = %v, want K", keys)
+	}
+
+	// Escape leaves the keys as they were
+	g = newScriptedGame(t, []ebiten.Key{ebiten.KeyEscape})
+	g.remap = Remap{set: ControlsSolo, action: ActionFire, waiting: true}
+	g.updateInput()
+	g.updateRemap()
+	defaults := defaultBindings()[ControlsSolo]
+	if g.remap.waiting || g.config.Keys[ControlsSolo].label(ActionFire) != defaults.label(ActionFire) {
+		t.Errorf("fire = %v after Escape, want the default", g.config.Keys[ControlsSolo][ActionFire])
+	}
+}
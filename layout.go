@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Size of the logical screen the game is drawn at, worked out by Layout from the window's size
+var screenWidth, screenHeight = baseWidth, baseHeight
+
+// Heights of the logical screen to choose from, a larger one fitting more in with smaller text
+var screenResolutions = []int{600, 720, 900, 1080}
+
+// AspectMode is how the logical screen fits a window that is not the game's 4:3 shape
+type AspectMode int
+
+const (
+	// Keep the screen 4:3 with black bars at the sides or the top and bottom
+	AspectLetterbox AspectMode = 0
+
+	// Widen or heighten the screen to fill the window, showing more of the playfield
+	AspectExpand AspectMode = 1
+
+	aspectModeCount = 2
+)
+
+func (a AspectMode) String() string {
+	if a == AspectExpand {
+		return tr("aspect.expand")
+	}
+	return tr("aspect.letterbox")
+}
+
+// Explains what the mode does with a window that is not 4:3
+func (a AspectMode) description() string {
+	if a == AspectExpand {
+		return tr("aspect.expand_about")
+	}
+	return tr("aspect.letterbox_about")
+}
+
+// Returns the size of the logical screen for a window, which is never smaller than 4:3 at the
+// chosen height so every screen laid out for 800x600 still fits
+func (c *Config) screenSize(outsideWidth, outsideHeight int) (int, int) {
+	h := c.Resolution
+	w := h * baseWidth / baseHeight
+	if c.Aspect != AspectExpand || outsideWidth <= 0 || outsideHeight <= 0 {
+		return w, h
+	}
+	if outsideWidth*baseHeight > outsideHeight*baseWidth {
+		return h * outsideWidth / outsideHeight, h
+	}
+	return w, w * outsideHeight / outsideWidth
+}
+
+// Label for the logical screen size, as shown on the video settings
+func screenSizeLabel() string {
+	return fmt.Sprintf("%dx%d", screenWidth, screenHeight)
+}
+
+// Index of a resolution in the list, or -1 if it is not one of them
+func screenResolutionIndex(h int) int {
+	for i, r := range screenResolutions {
+		if r == h {
+			return i
+		}
+	}
+	return -1
+}
+
+// Positions on screen measured from the right and bottom edges, so the HUD stays in the corners
+func fromRight(x int) int {
+	return screenWidth - x
+}
+
+func fromBottom(y int) int {
+	return screenHeight - y
+}
+
+// Left edge of an 800 pixel wide page centred on the screen, for screens laid out in columns
+func pageLeft() int {
+	return (screenWidth - baseWidth) / 2
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// UDP port hosts answer discovery on - hosts on the same machine take the next free port
+	discoveryPort  = 7778
+	discoveryPorts = 4
+
+	// Time spent listening for hosts, and how often the lobby searches again
+	discoveryTimeout  = 500 * time.Millisecond
+	discoveryInterval = 2 * time.Second
+
+	// Countdown once every player in the lobby is ready
+	lobbyCountdownTicks = 3 * ticksPerSecond
+
+	// Games listed in the lobby, each chosen with a number key
+	maxListedSessions = 9
+)
+
+// Hosted game found on the local network
+type SessionInfo struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Port     int      `json:"port"`
+	Players  []string `json:"players"`
+	Level    int      `json:"level"`
+	GameType GameType `json:"gameType"`
+	InLobby  bool     `json:"inLobby"`
+
+	// Address to join the game on, filled in by the client from where the answer came from
+	Addr string `json:"-"`
+}
+
+// Players waiting in the host's lobby
+type LobbyState struct {
+	Players   []LobbyPlayer `json:"players"`
+	Level     int           `json:"level"`
+	GameType  GameType      `json:"gameType"`
+	Countdown int           `json:"countdown,omitempty"`
+}
+
+// Player waiting in the lobby
+type LobbyPlayer struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+}
+
+// Lobby screen state
+type Lobby struct {
+	ready     bool
+	countdown int
+	status    string
+
+	// Menus for browsing, hosting and having joined a game
+	browser, hosting, joined *Menu
+
+	mu         sync.Mutex
+	sessions   []SessionInfo
+	searching  bool
+	lastSearch time.Time
+}
+
+// Answers discovery probes on the first free discovery port
+func (h *NetHost) StartDiscovery() error {
+
+	var err error
+	for port := discoveryPort; port < discoveryPort+discoveryPorts; port++ {
+		var conn *net.UDPConn
+		conn, err = net.ListenUDP("udp4", &net.UDPAddr{Port: port})
+		if err != nil {
+			continue
+		}
+		h.discovery = conn
+		go h.discoveryLoop(conn)
+		fmt.Printf("%s \n", tr("console.discovery", port))
+		return nil
+	}
+	return err
+}
+
+func (h *NetHost) discoveryLoop(conn *net.UDPConn) {
+
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		var probe NetMessage
+		if json.Unmarshal(buf[:n], &probe) != nil || probe.Type != msgDiscover || probe.Version != protocolVersion {
+			continue
+		}
+
+		session := h.sessionInfo()
+		reply, err := json.Marshal(NetMessage{Type: msgSession, Version: protocolVersion, Session: &session})
+		if err != nil {
+			continue
+		}
+		conn.WriteToUDP(reply, from)
+	}
+}
+
+// Hosts a game from the lobby on the default port, or on any free port when another game on this
+// machine already has it. Discovery tells players the port to join on.
+func newLobbyHost(name string) (*NetHost, error) {
+	h, err := NewNetHost(":"+defaultNetPort, name)
+	if err != nil {
+		h, err = NewNetHost(":0", name)
+	}
+	return h, err
+}
+
+// Updates the level and game type the host advertises
+func (h *NetHost) setSession(level int, gameType GameType, inLobby bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.session.Level = level
+	h.session.GameType = gameType
+	h.session.InLobby = inLobby
+}
+
+// Returns the session advertised to players looking for a game
+func (h *NetHost) sessionInfo() SessionInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.session
+	s.ID = h.sessionID
+	s.Name = h.name
+	if addr, ok := h.listener.Addr().(*net.TCPAddr); ok {
+		s.Port = addr.Port
+	}
+	s.Players = []string{h.name}
+	for id := 2; id <= maxNetPlayers; id++ {
+		if c := h.clients[id]; c != nil {
+			s.Players = append(s.Players, c.name)
+		}
+	}
+	return s
+}
+
+// Returns the clients waiting in the lobby
+func (h *NetHost) lobbyPlayers() []LobbyPlayer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var players []LobbyPlayer
+	for id := 2; id <= maxNetPlayers; id++ {
+		if c := h.clients[id]; c != nil {
+			players = append(players, LobbyPlayer{ID: id, Name: c.name, Ready: c.ready})
+		}
+	}
+	return players
+}
+
+// Looks for hosted games on the local network and the loopback interface
+func DiscoverSessions(timeout time.Duration) ([]SessionInfo, error) {
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	probe, err := json.Marshal(NetMessage{Type: msgDiscover, Version: protocolVersion})
+	if err != nil {
+		return nil, err
+	}
+	for port := discoveryPort; port < discoveryPort+discoveryPorts; port++ {
+		for _, ip := range []net.IP{net.IPv4bcast, net.IPv4(127, 0, 0, 1)} {
+			// Broadcast is not allowed everywhere, loopback still finds games on this machine
+			conn.WriteToUDP(probe, &net.UDPAddr{IP: ip, Port: port})
+		}
+	}
+
+	var sessions []SessionInfo
+	seen := make(map[int64]bool)
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// Read deadline reached
+			return sessions, nil
+		}
+
+		var reply NetMessage
+		if json.Unmarshal(buf[:n], &reply) != nil || reply.Type != msgSession || reply.Session == nil {
+			continue
+		}
+
+		// A host on this machine answers both the broadcast and the loopback probe
+		s := *reply.Session
+		if seen[s.ID] {
+			continue
+		}
+		seen[s.ID] = true
+		s.Addr = net.JoinHostPort(from.IP.String(), strconv.Itoa(s.Port))
+		sessions = append(sessions, s)
+	}
+}
+
+// Searches for games in the background every few seconds while browsing the lobby
+func (l *Lobby) search() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.searching || time.Since(l.lastSearch) < discoveryInterval {
+		return
+	}
+	l.searching = true
+
+	go func() {
+		sessions, err := DiscoverSessions(discoveryTimeout)
+		if err != nil {
+			fmt.Printf("%s \n", tr("console.error_searching", err))
+		}
+		l.mu.Lock()
+		l.sessions = sessions
+		l.searching = false
+		l.lastSearch = time.Now()
+		l.mu.Unlock()
+	}()
+}
+
+// Returns the games found by the last search
+func (l *Lobby) found() []SessionInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sessions
+}
+
+// Opens the lobby screen
+func (g *Game) enterLobby() {
+	g.mode = ModeLobby
+	g.lobby.ready = false
+	g.lobby.countdown = 0
+	g.lobby.status = ""
+	if g.level == 0 {
+		g.level = 1
+	}
+}
+
+// Builds the lobby's menus, so it can be used with the keyboard, a gamepad or by touch
+func newLobbyMenus() (browser, hosting, joined *Menu) {
+
+	toStart := func(g *Game) { g.mode = ModeStart }
+	stopHosting := func(g *Game) {
+		g.host.Close()
+		g.host = nil
+		g.mode = ModeStart
+	}
+
+	// A line for each game found, hidden until there is one to show
+	browser = &Menu{back: toStart}
+	for i := 0; i < maxListedSessions; i++ {
+		i := i
+		browser.items = append(browser.items, MenuItem{
+			label: func(g *Game) string {
+				// The search may have just replaced the list
+				sessions := g.lobby.found()
+				if i >= len(sessions) {
+					return ""
+				}
+				s := sessions[i]
+				state := tr("lobby.in_lobby")
+				if !s.InLobby {
+					state = tr("lobby.playing")
+				}
+				return tr("lobby.session", s.Name, len(s.Players), s.GameType, s.Level, state)
+			},
+			keys:   []ebiten.Key{ebiten.Key1 + ebiten.Key(i)},
+			action: func(g *Game) { g.joinSession(i) },
+			hidden: func(g *Game) bool { return i >= len(g.lobby.found()) },
+		})
+	}
+	browser.items = append(browser.items,
+		MenuItem{label: fixedLabel("lobby.host_game"), keys: []ebiten.Key{ebiten.KeyH}, action: func(g *Game) { g.hostLobby() }},
+		MenuItem{label: fixedLabel("menu.back"), keys: []ebiten.Key{ebiten.KeyB}, action: toStart},
+	)
+
+	hosting = &Menu{back: stopHosting, items: []MenuItem{
+		{
+			label:  func(g *Game) string { return readyLabel(g.lobby.ready) },
+			keys:   []ebiten.Key{ebiten.KeyR},
+			action: func(g *Game) { g.lobby.ready = !g.lobby.ready },
+		},
+		{
+			label: func(g *Game) string { return tr("menu.game_mode", g.gameType) },
+			keys:  []ebiten.Key{ebiten.KeyM},
+			change: func(g *Game, by int) {
+				g.gameType = (g.gameType + gameTypeCount + GameType(by)) % gameTypeCount
+			},
+		},
+		{
+			label: func(g *Game) string { return tr("lobby.level", g.level) },
+			keys:  []ebiten.Key{ebiten.KeyL},
+			change: func(g *Game, by int) {
+				g.level = (g.level-1+len(levelAsteroids)+by)%len(levelAsteroids) + 1
+			},
+		},
+		{label: fixedLabel("lobby.stop_hosting"), keys: []ebiten.Key{ebiten.KeyB}, action: stopHosting},
+	}}
+
+	// Leaving is also on the quit key and button, which work anywhere in a network game
+	joined = &Menu{items: []MenuItem{
+		{
+			label: func(g *Game) string { return readyLabel(g.view.ready) },
+			keys:  []ebiten.Key{ebiten.KeyR},
+			action: func(g *Game) {
+				g.view.ready = !g.view.ready
+				// A lost connection shows up in the next snapshots
+				g.client.SendReady(g.view.ready)
+			},
+			hidden: func(g *Game) bool { return g.client.spectator },
+		},
+		{
+			label: func(g *Game) string {
+				if g.client.spectator {
+					return tr("lobby.stop_watching")
+				}
+				return tr("lobby.leave")
+			},
+			action: func(g *Game) { g.leaveGame() },
+		},
+	}}
+	return browser, hosting, joined
+}
+
+// Menu line to ready up, or to stop being ready
+func readyLabel(ready bool) string {
+	if ready {
+		return tr("lobby.unready")
+	}
+	return tr("lobby.ready_up")
+}
+
+// Lobby update function - browsing for games, or waiting for players when hosting
+func (g *Game) updateLobby() {
+
+	if g.host == nil {
+		g.lobby.search()
+		g.lobby.browser.update(g)
+		return
+	}
+	g.lobby.hosting.update(g)
+	if g.host == nil {
+		return
+	}
+
+	// Start together once everyone is ready
+	if !g.lobbyReady() {
+		g.lobby.countdown = 0
+		return
+	}
+	if g.lobby.countdown == 0 {
+		g.lobby.countdown = lobbyCountdownTicks
+		fmt.Println(tr("console.lobby_ready"))
+	}
+	g.lobby.countdown--
+	if g.lobby.countdown == 0 {
+		g.init(levelAsteroids[g.level])
+		g.mode = ModePlay
+	}
+}
+
+// Checks if the host and every player who has joined are ready
+func (g *Game) lobbyReady() bool {
+	players := g.host.lobbyPlayers()
+	if !g.lobby.ready || len(players) == 0 {
+		return false
+	}
+	for _, p := range players {
+		if !p.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Starts hosting a game from the lobby
+func (g *Game) hostLobby() {
+	h, err := newLobbyHost(g.playerName)
+	if err != nil {
+		g.lobby.status = tr("lobby.host_failed", err)
+		return
+	}
+	if err := h.StartDiscovery(); err != nil {
+		fmt.Printf("%s \n", tr("console.error_discovery", err))
+	}
+	g.host = h
+	g.lobby.ready = false
+	fmt.Printf("%s \n", tr("console.hosting", h.Addr()))
+}
+
+// Joins a game listed in the lobby
+func (g *Game) joinSession(i int) {
+	sessions := g.lobby.found()
+	if i >= len(sessions) {
+		return
+	}
+	c, err := DialNetClient(sessions[i].Addr, g.playerName)
+	if err != nil {
+		g.lobby.status = tr("lobby.join_failed", sessions[i].Name, err)
+		return
+	}
+	g.client = c
+	g.view = netClientView{}
+	fmt.Printf("%s \n", tr("console.joined", sessions[i].Addr, c.PlayerID()))
+}
+
+// Returns the lobby sent to clients in each snapshot
+func (g *Game) lobbyState() *LobbyState {
+	players := append([]LobbyPlayer{{ID: 1, Name: g.playerName, Ready: g.lobby.ready}}, g.host.lobbyPlayers()...)
+	return &LobbyState{
+		Players:   players,
+		Level:     g.level,
+		GameType:  g.gameType,
+		Countdown: g.lobby.countdown,
+	}
+}
+
+// Draws the lobby screen
+func (g *Game) drawLobby(screen *ebiten.Image) {
+
+	drawCentredText(screen, tr("lobby.title"), g.fonts.heading, 50, g.theme.text)
+
+	switch {
+	case g.client != nil && g.view.lobby != nil:
+		if g.client.spectator {
+			drawCentredText(screen, tr("lobby.spectating"), g.fonts.small, 90, colourHint)
+		} else {
+			drawCentredText(screen, tr("lobby.joined", g.client.PlayerID()), g.fonts.small, 90, colourHint)
+		}
+		g.drawLobbyPlayers(screen, g.view.lobby)
+		g.lobby.joined.draw(g, screen, 320)
+
+	case g.host != nil:
+		drawCentredText(screen, tr("lobby.hosting", g.host.Addr()), g.fonts.small, 90, colourHint)
+		g.drawLobbyPlayers(screen, g.lobbyState())
+		g.lobby.hosting.draw(g, screen, 320)
+
+	default:
+		drawCentredText(screen, tr("lobby.browse"), g.fonts.small, 90, colourHint)
+		if len(g.lobby.found()) == 0 {
+			drawCentredText(screen, tr("lobby.searching"), g.fonts.body, 140, g.theme.text)
+		}
+		g.lobby.browser.draw(g, screen, 180)
+	}
+
+	if g.lobby.status != "" {
+		drawCentredText(screen, g.lobby.status, g.fonts.small, fromBottom(70), g.theme.highlight)
+	}
+	help := g.prompt(tr("start.menu_help"), tr("pad.menu_help", padLabel(ActionConfirm), padLabel(ActionBack)), tr("pointer.menu_help"))
+	drawCentredText(screen, help, g.fonts.small, fromBottom(45), colourHint)
+}
+
+// Draws who is in the lobby, whether they are ready and the chosen level
+func (g *Game) drawLobbyPlayers(screen *ebiten.Image, lobby *LobbyState) {
+
+	drawCentredText(screen, tr("lobby.game", lobby.GameType, lobby.Level), g.fonts.body, 130, g.theme.text)
+
+	for i, p := range lobby.Players {
+		ready := tr("lobby.not_ready")
+		if p.Ready {
+			ready = tr("lobby.ready")
+		}
+		drawCentredText(screen, tr("lobby.player", p.ID, p.Name, ready), g.fonts.small, 170+i*24, g.theme.text)
+	}
+
+	if lobby.Countdown > 0 {
+		drawCentredText(screen, tr("lobby.starting", lobby.Countdown/ticksPerSecond+1), g.fonts.body, 275, g.theme.highlight)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestDiscoveryFindsEveryHost(t *testing.T) {
+	hosts := []*NetHost{startTestHost(t, "first"), startTestHost(t, "second")}
+	for _, h := range hosts {
+		if err := h.StartDiscovery(); err != nil {
+			t.Fatalf("starting discovery: %v", err)
+		}
+	}
+
+	sessions, err := DiscoverSessions(discoveryTimeout)
+	if err != nil {
+		t.Fatalf("discovering: %v", err)
+	}
+	for _, h := range hosts {
+		found := false
+		for _, s := range sessions {
+			if s.ID == h.sessionID {
+				found = true
+				if s.Name != h.name || s.Port != h.Addr().(*net.TCPAddr).Port {
+					t.Errorf("session = %+v, want %s on port %v", s, h.name, h.Addr())
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s not found in %+v", h.name, sessions)
+		}
+	}
+}
+
+func TestLobbyHostsShareAMachine(t *testing.T) {
+	var ports []int
+	for _, name := range []string{"first", "second"} {
+		h, err := newLobbyHost(name)
+		if err != nil {
+			t.Fatalf("hosting %s: %v", name, err)
+		}
+		defer h.Close()
+		ports = append(ports, h.sessionInfo().Port)
+	}
+	if ports[0] == ports[1] {
+		t.Fatalf("both hosts advertise port %d", ports[0])
+	}
+}
+
+func TestLobbyReadyUp(t *testing.T) {
+	h := startTestHost(t, "host")
+	c := dialTestClient(t, h, "client")
+
+	ready := func(want bool) {
+		t.Helper()
+		if err := c.SendReady(want); err != nil {
+			t.Fatalf("sending ready: %v", err)
+		}
+		waitFor(t, "the ready state", func() bool {
+			players := h.lobbyPlayers()
+			return len(players) == 1 && players[0].Ready == want
+		})
+	}
+
+	players := h.lobbyPlayers()
+	if len(players) != 1 || players[0].ID != c.PlayerID() || players[0].Name != "client" || players[0].Ready {
+		t.Fatalf("lobby = %+v, want client waiting", players)
+	}
+	ready(true)
+	ready(false)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message catalogs that come with the game, one file per language named by its code, e.g. fr.json
+//
+//go:embed locales
+var embeddedLocales embed.FS
+
+const (
+	// Folder of catalogs, inside the user config directory and inside the game
+	localesDir = "locales"
+
+	// Language every other catalog is checked against, and used for any text a catalog is missing
+	defaultLocale = "en"
+
+	// Key holding each language's own name for itself
+	localeNameKey = "language.name"
+)
+
+// Catalog holds the text of the game in one language
+type Catalog struct {
+	code     string
+	messages map[string]string
+}
+
+func (c *Catalog) name() string {
+	if name, ok := c.messages[localeNameKey]; ok {
+		return name
+	}
+	return c.code
+}
+
+var (
+	// Every catalog found, English first, and the one in use
+	catalogs []*Catalog
+	catalog  *Catalog
+	english  *Catalog
+
+	// Keys already reported as missing, so each is only printed once
+	missingKeys   = make(map[string]bool)
+	missingKeysMu sync.Mutex
+)
+
+// Returns the text for a key in the chosen language, formatted with the arguments like fmt.Sprintf.
+// Text missing from the catalog is taken from English, and the key itself is shown if English has none.
+func tr(key string, args ...interface{}) string {
+
+	message, ok := "", false
+	if catalog != nil {
+		message, ok = catalog.messages[key]
+	}
+	if !ok && english != nil {
+		message, ok = english.messages[key]
+	}
+	if !ok {
+		missingKeysMu.Lock()
+		if !missingKeys[key] {
+			missingKeys[key] = true
+			fmt.Printf("Missing text for %q \n", key)
+		}
+		missingKeysMu.Unlock()
+		return key
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Returns the folder players put their own catalogs in
+func userLocalesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileDir, localesDir), nil
+}
+
+// Loads the built in catalogs and the player's own. A player's catalog replaces
+// a built in one with the same code, so a translation can be fixed without rebuilding the game.
+func loadCatalogs() {
+
+	found := make(map[string]*Catalog)
+
+	builtIn, _ := fs.Sub(embeddedLocales, localesDir)
+	for _, c := range readCatalogs(builtIn, "built in") {
+		found[c.code] = c
+	}
+	if path, err := userLocalesPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			for _, c := range readCatalogs(os.DirFS(path), path) {
+				found[c.code] = c
+			}
+		}
+	}
+
+	english = found[defaultLocale]
+	if english == nil {
+		english = &Catalog{code: defaultLocale, messages: map[string]string{}}
+	}
+
+	catalogs = []*Catalog{english}
+	for code, c := range found {
+		if code != defaultLocale {
+			catalogs = append(catalogs, c)
+		}
+	}
+	sort.Slice(catalogs[1:], func(i, j int) bool { return catalogs[i+1].code < catalogs[j+1].code })
+	catalog = english
+}
+
+// Reads every .json catalog in a folder. Its messages are printed before there is any text to
+// translate them with, so they are always in English.
+func readCatalogs(dir fs.FS, source string) []*Catalog {
+
+	files, err := fs.Glob(dir, "*.json")
+	if err != nil {
+		fmt.Printf("Locales: can not read %s: %v \n", source, err)
+		return nil
+	}
+
+	var list []*Catalog
+	for _, file := range files {
+		data, err := fs.ReadFile(dir, file)
+		if err != nil {
+			fmt.Printf("Locales: can not read %s from %s: %v \n", file, source, err)
+			continue
+		}
+		c := &Catalog{code: strings.TrimSuffix(file, ".json")}
+		if err := json.Unmarshal(data, &c.messages); err != nil {
+			fmt.Printf("Locales: %s from %s is not valid: %v \n", file, source, err)
+			continue
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+// Switches language, returning false if there is no catalog for the code
+func setLocale(code string) bool {
+	for _, c := range catalogs {
+		if c.code == code {
+			catalog = c
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the language code of the system, e.g. "fr" for LANG=fr_FR.UTF-8
+func systemLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" && value != "C" && value != "POSIX" {
+			return strings.ToLower(strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '.' || r == '-' })[0])
+		}
+	}
+	return defaultLocale
+}
+
+// Chooses the saved language when the game starts, or the system's if none has been chosen
+func (g *Game) loadLocale() {
+	code := systemLocale()
+	if g.profile != nil && g.profile.Language != "" {
+		code = g.profile.Language
+	}
+	setLocale(code)
+}
+
+// Switches to the next or previous language and saves the choice
+func (g *Game) changeLocale(by int) {
+	i := 0
+	for j, c := range catalogs {
+		if c == catalog {
+			i = j
+		}
+	}
+	catalog = catalogs[(i+len(catalogs)+by)%len(catalogs)]
+
+	if g.profile != nil {
+		g.profile.Language = catalog.code
+		if err := g.profile.save(); err != nil {
+			fmt.Printf("%s \n", tr("console.error_saving_player_data", err))
+		}
+	}
+}
+
+// Formatting verbs in a message, e.g. %d, %.1f or %[2]s, but not %%
+var formatVerb = regexp.MustCompile(`%(\[[0-9]+\])?[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// Returns the verbs in a message with the argument each formats, e.g. "2:%s" for %[2]s, sorted so
+// a translation can take the arguments in a different order
+func verbs(message string) []string {
+	var found []string
+	arg := 1
+	for _, m := range formatVerb.FindAllStringSubmatch(message, -1) {
+		if m[0] == "%%" {
+			continue
+		}
+		verb := m[0]
+		if m[1] != "" {
+			arg, _ = strconv.Atoi(m[1][1 : len(m[1])-1])
+			verb = "%" + verb[len(m[1])+1:]
+		}
+		found = append(found, fmt.Sprintf("%d:%s", arg, verb))
+		arg++
+	}
+	sort.Strings(found)
+	return found
+}
+
+// Checks every catalog against English - missing and unknown keys, messages whose formatting verbs
+// do not match, and characters none of the fonts can draw. Returns a line for each problem.
+func checkCatalogs(fonts Fonts) []string {
+
+	var problems []string
+	for _, c := range catalogs {
+		for key, message := range c.messages {
+			if missing := fonts.missingGlyphs(message); len(missing) > 0 {
+				problems = append(problems, tr("console.locale_glyphs", c.code, key, string(missing)))
+			}
+		}
+		if c == english {
+			continue
+		}
+
+		for key, message := range english.messages {
+			translated, ok := c.messages[key]
+			if !ok {
+				problems = append(problems, tr("console.locale_untranslated", c.code, key))
+				continue
+			}
+			if fmt.Sprint(verbs(translated)) != fmt.Sprint(verbs(message)) {
+				problems = append(problems, tr("console.locale_verbs", c.code, key, verbs(translated), verbs(message)))
+			}
+		}
+		for key := range c.messages {
+			if _, ok := english.messages[key]; !ok {
+				problems = append(problems, tr("console.locale_unused", c.code, key))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCatalogs(t *testing.T) {
+	for _, problem := range checkCatalogs(loadFonts()) {
+		t.Error(problem)
+	}
+}
+
+func TestVerbsMatchByArgument(t *testing.T) {
+	tests := []struct {
+		english, translated string
+		match               bool
+	}{
+		{"%s scored %d", "%s a marqué %d", true},
+		{"%s scored %d", "%[2]d points pour %[1]s", true},
+		{"%s scored %d", "%[2]d points pour %s", false},
+		{"%d%% of %s", "%[2]s : %[1]d %%", true},
+		{"%s scored %d", "%d points pour %s", false},
+		{"%s scored %.1f", "%s a marqué %d", false},
+		{"%s and %s", "%[1]s et %[1]s", false},
+	}
+	for _, test := range tests {
+		got := fmt.Sprint(verbs(test.translated)) == fmt.Sprint(verbs(test.english))
+		if got != test.match {
+			t.Errorf("%q against %q: match = %v, want %v (%v, %v)",
+				test.translated, test.english, got, test.match, verbs(test.translated), verbs(test.english))
+		}
+	}
+}
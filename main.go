@@ -2,9 +2,9 @@ package main
 
 // Game/GoLang Imports
 import (
+	"flag"
 	"fmt"
 	"image/color"
-	_ "image/png"
 	"log"
 	"math"
 	"math/rand"
@@ -14,7 +14,6 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 // Game Mode Type include -> [Playing, Paused, Lost, Win, Menus]
@@ -24,16 +23,37 @@ type Mode int
 const (
 
 	// Different Game Levels
-	ModeStart  Mode = 0
-	ModeLevels Mode = 1
-	ModePlay   Mode = 3
-	ModePause  Mode = 4
-	ModeOver   Mode = 5
-	ModeWon    Mode = 6
-
-	// Game Window Size
-	windowWidth  = 800
-	windowHeight = 600
+	ModeStart    Mode = 0
+	ModeLevels   Mode = 1
+	ModePlay     Mode = 3
+	ModePause    Mode = 4
+	ModeOver     Mode = 5
+	ModeWon      Mode = 6
+	ModeResult   Mode = 7
+	ModeLobby    Mode = 8
+	ModeAwards   Mode = 9
+	ModeStats    Mode = 10
+	ModeSettings Mode = 11
+
+	// Settings screens reached from the settings menu
+	ModeVideo         Mode = 12
+	ModeAudio         Mode = 13
+	ModeControls      Mode = 14
+	ModeGameplay      Mode = 15
+	ModeLearning      Mode = 16
+	ModeAppearance    Mode = 17
+	ModeAccessibility Mode = 18
+
+	// Size the game was designed at, which is the window's size at a scale of 1
+	baseWidth  = 800
+	baseHeight = 600
+
+	// Size of the world, larger than the window - the camera follows the ship through it
+	worldWidth  = 1600
+	worldHeight = 1200
+
+	// Distance a rocket flies before it returns to the ship
+	rocketRange = baseHeight
 
 	// Game Assets Sizes
 	shipWidth          = 50
@@ -64,6 +84,9 @@ var (
 	// Variables for recording how many goroutines are generated
 	generationGoroutines uint32
 	updateGoroutines     uint32
+
+	// Last id given to an asteroid, so each can be followed over the network
+	asteroidIDs uint32
 )
 
 // Game Object Type
@@ -73,11 +96,6 @@ type Game struct {
 	ship                 *ebiten.Image
 	rocket               *ebiten.Image
 	gameLogo             *ebiten.Image
-	gameInstructions     *ebiten.Image
-	gameLevels           *ebiten.Image
-	gameOver             *ebiten.Image
-	gamePaused           *ebiten.Image
-	gameWon              *ebiten.Image
 	gameConcurrencyRadar *ebiten.Image
 	gamePlayerHealth     *ebiten.Image
 
@@ -86,12 +104,18 @@ type Game struct {
 
 	// Game Object Coordinates
 	asteroidXPos, asteroidYPos float64
-	shipXPos, shipYPos         float64
-	rocketXPos, rocketYPos     float64
 
-	mode         Mode
-	shooting     bool
-	playerHealth int
+	mode Mode
+
+	// Players in the game, with the options for two player co-op
+	players      []*Player
+	coop         bool
+	friendlyFire bool
+
+	// Progress through a versus match
+	round       int
+	roundTicks  int
+	roundWinner int
 
 	asteroids     Asteroids
 	miniAsteroids Asteroids
@@ -99,11 +123,85 @@ type Game struct {
 	inited        bool
 	stars         [1024]Star
 	MinDifficulty int
+
+	// Chosen game type and level with the progress through it
+	gameType  GameType
+	level     int
+	ticks     int
+	wave      int
+	lastScore int
+	newBest   bool
+
+	// Player data and settings saved between sessions, and the window scale last set from them
+	profile     *Profile
+	config      *Config
+	windowScale float64
+
+	// Menus for each screen, and the fonts they are drawn with
+	menus map[Mode]*Menu
+	fonts Fonts
+
+	// Keyboard or script the game is played with, the key being changed on the controls screen,
+	// and for each set of controls whether firing has been switched on with a press
+	input       InputSource
+	remap       Remap
+	fireToggled [controlSetCount]bool
+
+	// Gamepads plugged in and the players flying with them, the mouse and touches, and which was used last
+	pads    Gamepads
+	pointer Pointer
+	device  Device
+
+	// Screen the settings go back to, the start screen or the pause screen
+	settingsReturn Mode
+
+	// Where the images are loaded from, and the theme they are drawn with
+	assets *Assets
+	theme  *Theme
+	themes []*Theme
+
+	// Concurrency strategy used to update the asteroids
+	strategy ConcurrencyStrategy
+
+	// Statistics of the game being played
+	session SessionStats
+
+	// Drawn as images or as vector polygons, which are then also used for collisions
+	asteroidStyle AsteroidStyle
+
+	// Adaptive difficulty, adjusted during play when turned on
+	adaptive   bool
+	difficulty Difficulty
+
+	// View of the world, following the ship
+	camera Camera
+
+	// Explosions, thrust and other effects
+	particles ParticleSystem
+
+	// Sound effects and music, and the screen the music was last chosen for
+	audio    *Audio
+	lastMode Mode
+
+	// Messages shown on screen, e.g. unlocked achievements
+	notifications []notification
+
+	// Network multiplayer - set when hosting or when joined to a host
+	host       *NetHost
+	client     *NetClient
+	view       netClientView
+	netTick    int
+	lobby      Lobby
+	playerName string
+
+	// Live state stream for spectators, set when started with -stream
+	stream *SpectatorServer
 }
 
 // Asteroid Object Type
 
 type Asteroid struct {
+	id     uint32
 	width  int
 	height int
 	x      float64
@@ -111,6 +209,12 @@ type Asteroid struct {
 	vx     float64
 	vy     float64
 	angle  float64
+
+	// Set while the asteroid flies in from off screen, before it starts bouncing off the edges
+	entering bool
+
+	// Polygon drawn for vector asteroids, made from the id when first needed
+	shape []point
 }
 
 // Asteroids type containts list of tpe Asteroid
@@ -130,22 +234,31 @@ func (g *Game) init(difficulty int) {
 		g.inited = true
 	}()
 
-	g.playerHealth = 100
+	g.ticks = 0
+	g.wave = 0
 	MinDifficulty = difficulty
 
+	generationGoroutines = 0
+	updateGoroutines = 0
+	g.session = SessionStats{}
+	g.particles.clear()
+	g.resetCamera()
+	g.initDifficulty()
+
+	g.initPlayers()
+	g.initVersus()
+
+	resetAsteroids(g)
+}
+
+// Clears the field and generates a new set of asteroids
+func resetAsteroids(g *Game) {
+
 	g.asteroids.asteroidsList = make([]*Asteroid, MinDifficulty, maxDifficulty)
 	g.miniAsteroids.asteroidsList = make([]*Asteroid, maxDifficulty)
 
 	AsteroidsInGame = len(g.asteroids.asteroidsList)
 	miniAsteroidsInGame = 0
-	generationGoroutines = 0
-	updateGoroutines = 0
-
-	g.shipXPos = float64(windowWidth/2) - float64(shipWidth/2)
-	g.shipYPos = float64(windowHeight) - float64(shipHeight*2)
-
-	g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-	g.rocketYPos = g.shipYPos + float64(shipHeight/2)
 
 	generateAsteroids(g)
 }
@@ -155,6 +268,11 @@ func generateAsteroids(g *Game) {
 
 	var wg sync.WaitGroup
 
+	// Starting positions are chosen together so no asteroid starts on a ship or on another asteroid
+	positions := safePositions(len(g.asteroids.asteroidsList), g.shipCentres())
+	g.asteroids.asteroidsList = g.asteroids.asteroidsList[:len(positions)]
+	AsteroidsInGame = len(positions)
+
 	for i := range g.asteroids.asteroidsList {
 
 		wg.Add(1)
@@ -162,10 +280,11 @@ func generateAsteroids(g *Game) {
 			atomic.AddUint32(&generationGoroutines, 1)
 			w := asteroidWidth
 			h := asteroidHeight
-			x, y := rand.Intn(windowWidth-w), rand.Intn((windowHeight-h)/2)
+			x, y := positions[i].x-float64(w/2), positions[i].y-float64(h/2)
 			vx, vy := 2*rand.Intn(2)-1, 2*rand.Intn(2)-1
 			a := rand.Intn(maxAngle)
 			g.asteroids.asteroidsList[i] = &Asteroid{
+				id:     atomic.AddUint32(&asteroidIDs, 1),
 				width:  w,
 				height: h,
 				x:      float64(x),
@@ -174,61 +293,49 @@ func generateAsteroids(g *Game) {
 				vy:     float64(vy),
 				angle:  float64(a),
 			}
-			fmt.Printf("Generation Go routine %d finished \n", i)
+			fmt.Printf("%s \n", tr("console.generation_goroutine", i))
 			wg.Done()
 		}(i)
 	}
 
 	wg.Wait()
-	fmt.Printf("%d Asteroids Generated concurrently \n", len(g.asteroids.asteroidsList))
+	fmt.Printf("%s \n", tr("console.generated", len(g.asteroids.asteroidsList)))
 
 }
 
 // Concurrent Update function for Asteroids in game
-func (s *Asteroids) Update() {
-
-	var wg sync.WaitGroup
-
-	for i := 0; i < AsteroidsInGame; i++ {
-
-		wg.Add(1)
-		go func(i int) {
-			atomic.AddUint32(&updateGoroutines, 1)
-			s.asteroidsList[i].Update()
-			wg.Done()
-		}(i)
-	}
-
-	wg.Wait()
+func (s *Asteroids) Update(strategy ConcurrencyStrategy) {
+	strategy.update(s.asteroidsList[:AsteroidsInGame])
 }
 
-func (s *Asteroids) miniUpdate() {
-
-	var wg sync.WaitGroup
-
-	for i := 0; i < miniAsteroidsInGame; i++ {
-
-		wg.Add(1)
-		go func(i int) {
-			atomic.AddUint32(&updateGoroutines, 1)
-			s.asteroidsList[i].Update()
-			wg.Done()
-		}(i)
-	}
-
-	wg.Wait()
+func (s *Asteroids) miniUpdate(strategy ConcurrencyStrategy) {
+	strategy.update(s.asteroidsList[:miniAsteroidsInGame])
 }
 
 // Update function for individual asteroids
 func (s *Asteroid) Update() {
 
-	s.x += s.vx
-	s.y += s.vy
+	s.x += s.vx * asteroidSpeed
+	s.y += s.vy * asteroidSpeed
+
+	if s.entering {
+		// Bounce off the edges only once fully on screen. One that has drifted further off the side
+		// than an entering asteroid starts is bounced back in, rather than leaving the world for good.
+		s.entering = s.x < 0 || s.y < 0 || s.x > float64(worldWidth-s.width) || s.y > float64(worldHeight-s.height)
+		if s.x < -float64(s.width) || s.x > float64(worldWidth) {
+			s.entering = false
+		}
+		s.angle++
+		if s.angle >= maxAngle {
+			s.angle = 0
+		}
+		return
+	}
 
 	if s.x < 0 {
 		s.x = -s.x
 		s.vx = -s.vx
-	} else if mx := float64(windowWidth) - float64(s.width); mx <= s.x {
+	} else if mx := float64(worldWidth) - float64(s.width); mx <= s.x {
 		s.x = 2*mx - s.x
 		s.vx = -s.vx
 	}
@@ -236,7 +343,7 @@ func (s *Asteroid) Update() {
 	if s.y < 0 {
 		s.y = -s.y
 		s.vy = -s.vy
-	} else if my := float64(windowHeight) - float64(s.height); my <= s.y {
+	} else if my := float64(worldHeight) - float64(s.height); my <= s.y {
 		s.y = 2*my - s.y
 		s.vy = -s.vy
 	}
@@ -251,224 +358,196 @@ func (s *Asteroid) Update() {
 // Update function
 func (g *Game) Update() error {
 
+	g.updateInput()
+	g.updateGameSpeed()
+
+	// Camera, sound and messages follow the game once everything has moved this tick
+	defer g.updateCamera()
+	defer g.updateAudio()
+	defer g.updateNotifications()
+
+	// Network clients only send input and draw what the host sends back
+	if g.client != nil {
+		return g.updateClient()
+	}
+	if g.host != nil {
+		g.updateHost()
+	}
+	if g.host != nil || g.stream != nil {
+		defer g.publishSnapshot()
+	}
+
 	switch g.mode {
-	case ModeStart:
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.KeySpace {
-				g.mode = ModeLevels
-			} else if x == ebiten.KeyQ {
-				fmt.Println("Thanks for playing!")
-				os.Exit(1)
-			}
+	case ModeStart, ModeSettings, ModeVideo, ModeAudio, ModeGameplay, ModeLearning, ModeAppearance, ModeAccessibility:
+		g.menus[g.mode].update(g)
+	case ModeControls:
+		if g.remap.waiting {
+			g.updateRemap()
+		} else {
+			g.menus[ModeControls].update(g)
 		}
-	case ModeLevels:
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.Key1 {
-				if !g.inited {
-					g.init(5)
-					g.mode = ModePlay
-				}
-			} else if x == ebiten.Key2 {
-				if !g.inited {
-					g.init(10)
-					g.mode = ModePlay
-				}
-			} else if x == ebiten.Key3 {
-				if !g.inited {
-					g.init(20)
-					g.mode = ModePlay
-				}
-			}
+	case ModeLobby:
+		g.updateLobby()
+	case ModeAwards, ModeStats:
+		_, _, pressed := g.pointer.press()
+		if g.actionPressed(ControlsSolo, ActionBack) || pressed {
+			g.mode = ModeStart
 		}
+	case ModeLevels:
+		g.menus[ModeLevels].update(g)
 	case ModePlay:
-		// capture user input using Ebiten input utils
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.KeyRight {
-				g.shipXPos += 10
-				g.rocketXPos += 10
-			} else if x == ebiten.KeyLeft {
-				g.shipXPos -= 10
-				g.rocketXPos -= 10
-			} else if x == ebiten.KeyDown {
-				g.shipYPos += 10
-				g.rocketYPos += 10
-			} else if x == ebiten.KeyUp {
-				g.shipYPos -= 4
-				g.rocketYPos -= 4
-			} else if x == ebiten.KeyRight {
-				g.shipXPos += 10
-				g.rocketXPos += 10
-			} else if x == ebiten.KeyA {
-				g.shipXPos -= 10
-				g.rocketXPos -= 10
-			} else if x == ebiten.KeyS {
-				g.shipYPos += 10
-				g.rocketYPos += 10
-			} else if x == ebiten.KeyW {
-				g.shipYPos -= 4
-				g.rocketYPos -= 4
-			} else if x == ebiten.KeyD {
-				g.shipXPos += 10
-				g.rocketXPos += 10
-			} else if x == ebiten.KeySpace {
-				g.shooting = true
-			} else if x == ebiten.KeyP {
-				g.mode = ModePause
-			}
+		if g.actionPressed(ControlsSolo, ActionPause) || g.pausePressed() {
+			g.mode = ModePause
 		}
-
-		// Do not allow ship to fly out of bounds
-		// - Don't allow ship to pass side boundaries
-		if g.shipXPos >= float64(windowWidth)-float64(shipWidth) {
-			g.shipXPos = float64(windowWidth) - float64(shipWidth)
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
-		}
-		if g.shipXPos <= 0 {
-			g.shipXPos = 0
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
+		if g.host != nil {
+			g.discardIdleInputs()
 		}
 
-		// - Don't allow ship to pass top-down boundaries
-		if g.shipYPos <= 0 {
-			g.shipYPos = 0
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
-		}
-		if g.shipYPos >= float64(windowHeight)-float64(shipHeight) {
-			g.shipYPos = float64(windowHeight) - float64(shipHeight)
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
-		}
+		for _, p := range g.players {
+			if !p.alive() {
+				continue
+			}
+			firing, startY := p.shooting, p.shipYPos
+			if p.remote {
+				for _, in := range g.host.takeInputs(p.id) {
+					p.applyInput(in)
+				}
+			} else {
+				p.applyInput(g.controlState(p.controls))
+			}
+			p.keepInBounds()
 
-		// shooting rocket
-		if g.shooting {
-			g.shootRocket()
-		}
-		if g.rocketYPos <= 0 {
-			g.shooting = false
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
-		}
-		if g.rocketYPos <= g.asteroidYPos+float64(asteroidHeight) && g.rocketXPos <= g.asteroidXPos+float64(asteroidHeight) && g.rocketXPos >= g.asteroidXPos {
-			g.shooting = false
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
-		}
+			// Thrust while the ship moves forward
+			if p.shipYPos < startY {
+				g.particles.emit(thrustEmitter, p.shipXPos+shipWidth/2, p.shipYPos+shipHeight)
+			}
 
-		// Check if rocket has hit an asteroid
-		x, y := g.hit()
-		if x != -1 || y != -1 {
-			var wg sync.WaitGroup
-			for i := miniAsteroidsInGame; i < miniAsteroidsInGame+2 && i < maxDifficulty; i++ {
-				wg.Add(1)
-				go func(i int) {
-					atomic.AddUint32(&generationGoroutines, 1)
-					w := miniAsteroidWidth
-					h := miniAsteroidWidth
-					x, y := x+rand.Float64()*(500-400), y+rand.Float64()*(500-400)
-					vx, vy := 3*rand.Intn(2)-1, 2*rand.Intn(2)-1
-					a := rand.Intn(maxAngle)
-					g.miniAsteroids.asteroidsList[i] = &Asteroid{
-						width:  w,
-						height: h,
-						x:      float64(x),
-						y:      float64(y),
-						vx:     float64(vx),
-						vy:     float64(vy),
-						angle:  float64(a),
-					}
-					miniAsteroidsInGame = miniAsteroidsInGame + 1
-					fmt.Printf("Split off Go routine %d finished, new mini asteroid generated \n", i)
-					wg.Done()
-				}(i)
+			// shooting rocket
+			if p.shooting && !firing {
+				g.session.Shots++
+				g.audio.play(SoundFire)
 			}
-			wg.Wait()
+			if p.shooting {
+				p.shootRocket()
+			}
+			if p.rocketYPos <= 0 || p.rocketYPos < p.shipYPos-rocketRange {
+				p.resetRocket()
+			}
+			if p.rocketYPos <= g.asteroidYPos+float64(asteroidHeight) && p.rocketXPos <= g.asteroidXPos+float64(asteroidHeight) && p.rocketXPos >= g.asteroidXPos {
+				p.resetRocket()
+			}
+
+			// Check if rocket has hit an asteroid
+			x, y := g.hit(p)
+			if x != -1 || y != -1 {
+				// Make room for the split off mini asteroids
+				for len(g.miniAsteroids.asteroidsList) < maxDifficulty {
+					g.miniAsteroids.asteroidsList = append(g.miniAsteroids.asteroidsList, nil)
+				}
+
+				var wg sync.WaitGroup
+				splits := miniAsteroidsInGame + g.difficulty.splits
+				for i := miniAsteroidsInGame; i < splits && i < maxDifficulty; i++ {
+					wg.Add(1)
+					go func(i int) {
+						atomic.AddUint32(&generationGoroutines, 1)
+						w := miniAsteroidWidth
+						h := miniAsteroidWidth
+						x, y := x+rand.Float64()*(500-400), y+rand.Float64()*(500-400)
+						vx, vy := 3*rand.Intn(2)-1, 2*rand.Intn(2)-1
+						a := rand.Intn(maxAngle)
+						g.miniAsteroids.asteroidsList[i] = &Asteroid{
+							id:     atomic.AddUint32(&asteroidIDs, 1),
+							width:  w,
+							height: h,
+							x:      float64(x),
+							y:      float64(y),
+							vx:     float64(vx),
+							vy:     float64(vy),
+							angle:  float64(a),
+						}
+						miniAsteroidsInGame = miniAsteroidsInGame + 1
+						fmt.Printf("%s \n", tr("console.split_goroutine", i))
+						wg.Done()
+					}(i)
+				}
+				wg.Wait()
+				g.audio.play(SoundSplit)
+			}
+
+			// Check if rocket has hit an asteroid
+			g.miniHit(p)
 		}
 
-		// Check if rocket has hit an asteroid
-		g.miniHit()
+		// Check if players have shot each other
+		if g.gameType == TypeVersus {
+			g.friendlyFireCheck(versusHitDamage)
+		} else if g.friendlyFire {
+			g.friendlyFireCheck(friendlyFireDamage)
+		}
 
 		// Check for collission with asteroids
 		g.collissonCheck()
 
 		// Update asteroid trajectory/movement
-		g.asteroids.Update()
-		g.miniAsteroids.miniUpdate()
+		g.asteroids.Update(g.strategy)
+		g.miniAsteroids.miniUpdate(g.strategy)
+
+		// Advance timers and spawning for the chosen game type
+		g.updateGameType()
+		g.updateDifficulty()
+		g.particles.update()
+
+		// Versus ships respawn, with the match played over rounds
+		if g.gameType == TypeVersus {
+			g.updateVersus()
+			break
+		}
 
-		// Check if player health remains above 0
-		if g.playerHealth <= 0 {
-			g.mode = ModeOver
+		// Check if any player's health remains above 0
+		if !g.playersAlive() {
+			g.finishGame(ModeOver)
 		}
 
 		// Check if player has blown up all asteroids
-		if AsteroidsInGame == 0 && miniAsteroidsInGame == 0 {
-			g.mode = ModeWon
+		if g.mode == ModePlay && g.fieldCleared() {
+			g.finishGame(ModeWon)
 		}
-
 	case ModePause:
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.KeyR {
-				g.mode = ModePlay
-			} else if x == ebiten.KeyQ {
-				os.Exit(1)
-			} else if x == ebiten.KeyM {
-				g.inited = false
-				g.mode = ModeStart
-			}
-		}
-	case ModeOver:
+		g.menus[ModePause].update(g)
+	case ModeOver, ModeWon, ModeResult:
 		g.inited = false
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.KeyR {
-				g.miniAsteroids.asteroidsList = g.miniAsteroids.asteroidsList[:0]
-				g.mode = ModeLevels
-			} else if x == ebiten.KeyQ {
-				fmt.Println("Thanks for playing!")
-				os.Exit(1)
-			}
-		}
-	case ModeWon:
-		g.inited = false
-		for _, x := range inpututil.PressedKeys() {
-			if x == ebiten.KeyP {
-				g.mode = ModeStart
-			} else if x == ebiten.KeyQ {
-				fmt.Println("Thanks for playing!")
-				os.Exit(1)
-			}
-		}
+		g.menus[g.mode].update(g)
 	}
 	return nil
 }
 
 // Moves rocket position when firing
-func (g *Game) shootRocket() {
+func (p *Player) shootRocket() {
 	// rocket position
-	g.rocketYPos -= 15
+	p.rocketYPos -= 15
 }
 
-// Checks if rocket has hit an asteroid
-func (g *Game) hit() (float64, float64) {
+// Checks if a player's rocket has hit an asteroid
+func (g *Game) hit(p *Player) (float64, float64) {
 
 	var x, y float64 = -1, -1
 	w, h := g.rocket.Size()
 
 	for i := 0; i < AsteroidsInGame; i++ {
 
-		if g.rocketXPos < g.asteroids.asteroidsList[i].x+asteroidWidth &&
-			g.rocketXPos+float64(w) > g.asteroids.asteroidsList[i].x &&
-			g.rocketYPos < g.asteroids.asteroidsList[i].y+asteroidHeight &&
-			g.rocketYPos+float64(h) > g.asteroids.asteroidsList[i].y {
+		if g.asteroidTouches(g.asteroids.asteroidsList[i], asteroidWidth, asteroidHeight, p.rocketXPos, p.rocketYPos, float64(w), float64(h)) {
 
-			g.shooting = false
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
+			p.resetRocket()
 			x = g.asteroids.asteroidsList[i].x
 			y = g.asteroids.asteroidsList[i].y
+			g.particles.emit(impactEmitter, p.rocketXPos, p.rocketYPos)
+			g.particles.emit(explosionEmitter, x+asteroidWidth/2, y+asteroidHeight/2)
+			g.shake(shakeExplosion)
 			g.asteroids.asteroidsList = blowUp(g.asteroids.asteroidsList, i)
 			AsteroidsInGame = AsteroidsInGame - 1
+			p.score += asteroidPoints
+			g.asteroidDestroyed(false)
 
 		}
 	}
@@ -476,74 +555,85 @@ func (g *Game) hit() (float64, float64) {
 	return x, y
 }
 
-// Checks if rocket has hit a mini asteroid
-func (g *Game) miniHit() {
+// Checks if a player's rocket has hit a mini asteroid
+func (g *Game) miniHit(p *Player) {
 
 	w, h := g.rocket.Size()
 	for i := 0; i < miniAsteroidsInGame; i++ {
 
-		if g.rocketXPos < g.miniAsteroids.asteroidsList[i].x+miniAsteroidWidth &&
-			g.rocketXPos+float64(w) > g.miniAsteroids.asteroidsList[i].x &&
-			g.rocketYPos < g.miniAsteroids.asteroidsList[i].y+miniAsteroidHeight &&
-			g.rocketYPos+float64(h) > g.miniAsteroids.asteroidsList[i].y {
+		if g.asteroidTouches(g.miniAsteroids.asteroidsList[i], miniAsteroidWidth, miniAsteroidHeight, p.rocketXPos, p.rocketYPos, float64(w), float64(h)) {
 
-			g.shooting = false
-			g.rocketXPos = g.shipXPos + float64(shipWidth/2) - 1.5
-			g.rocketYPos = g.shipYPos + float64(shipHeight/2)
+			a := g.miniAsteroids.asteroidsList[i]
+			g.particles.emit(miniExplosionEmitter, a.x+miniAsteroidWidth/2, a.y+miniAsteroidHeight/2)
+			g.particles.emit(impactEmitter, p.rocketXPos, p.rocketYPos)
+			p.resetRocket()
 			g.miniAsteroids.asteroidsList = blowUp(g.miniAsteroids.asteroidsList, i)
 			miniAsteroidsInGame = miniAsteroidsInGame - 1
+			p.score += miniAsteroidPoints
+			g.asteroidDestroyed(true)
 
 		}
 	}
 }
 
 // Function to Reduce Player Health
-func reduce_health(g *Game, wg *sync.WaitGroup) {
+func reduce_health(p *Player, wg *sync.WaitGroup) {
 	mu.Lock()
-	g.playerHealth -= 1
+	p.health -= 1
 	mu.Unlock()
 	wg.Done()
 }
 
 // Check for collisions
 func (g *Game) collissonCheck() {
-	g.ship_hit_asteroid()
-	g.ship_hit_mini_asteroid()
+	for _, p := range g.players {
+		if !p.alive() || p.invulnerable > 0 {
+			continue
+		}
+		g.ship_hit_asteroid(p)
+		g.ship_hit_mini_asteroid(p)
+	}
 }
 
 // Checks if ship has collided with a asteroid
-func (g *Game) ship_hit_asteroid() {
+func (g *Game) ship_hit_asteroid(p *Player) {
 
 	for i := 0; i < AsteroidsInGame; i++ {
 
-		if g.shipXPos < g.asteroids.asteroidsList[i].x+asteroidWidth &&
-			g.shipXPos+shipWidth > g.asteroids.asteroidsList[i].x &&
-			g.shipYPos < g.asteroids.asteroidsList[i].y+asteroidHeight &&
-			shipHeight+g.shipYPos > g.asteroids.asteroidsList[i].y {
+		if g.asteroidTouches(g.asteroids.asteroidsList[i], asteroidWidth, asteroidHeight, p.shipXPos, p.shipYPos, shipWidth, shipHeight) {
 
 			var wg sync.WaitGroup
 			wg.Add(1)
-			go reduce_health(g, &wg)
+			go reduce_health(p, &wg)
 			wg.Wait()
+			g.session.DamageTaken++
+			g.particles.emit(shieldEmitter, p.shipXPos+shipWidth/2, p.shipYPos+shipHeight/2)
+			if !p.remote {
+				g.shake(shakeShipHit)
+				g.audio.play(SoundDamage)
+			}
 
 		}
 	}
 }
 
 // Checks if ship has collided with a mini asteroid
-func (g *Game) ship_hit_mini_asteroid() {
+func (g *Game) ship_hit_mini_asteroid(p *Player) {
 
 	for i := 0; i < miniAsteroidsInGame; i++ {
 
-		if g.shipXPos < g.miniAsteroids.asteroidsList[i].x+miniAsteroidWidth &&
-			g.shipXPos+shipWidth > g.miniAsteroids.asteroidsList[i].x &&
-			g.shipYPos < g.miniAsteroids.asteroidsList[i].y+miniAsteroidHeight &&
-			shipHeight+g.shipYPos > g.miniAsteroids.asteroidsList[i].y {
+		if g.asteroidTouches(g.miniAsteroids.asteroidsList[i], miniAsteroidWidth, miniAsteroidHeight, p.shipXPos, p.shipYPos, shipWidth, shipHeight) {
 
 			var wg sync.WaitGroup
 			wg.Add(1)
-			go reduce_health(g, &wg)
+			go reduce_health(p, &wg)
 			wg.Wait()
+			g.session.DamageTaken++
+			g.particles.emit(shieldEmitter, p.shipXPos+shipWidth/2, p.shipYPos+shipHeight/2)
+			if !p.remote {
+				g.shake(shakeShipHit)
+				g.audio.play(SoundDamage)
+			}
 
 		}
 	}
@@ -557,26 +647,61 @@ func blowUp(asteroids []*Asteroid, index int) []*Asteroid {
 // Drawing functions - to render images on screen
 func (g *Game) Draw(screen *ebiten.Image) {
 
-	screen.Fill(color.Black)
-	g.drawStars(screen)
+	// High contrast drops the theme's background and the stars for plain black
+	if g.config.HighContrast {
+		screen.Fill(color.Black)
+	} else {
+		screen.Fill(g.theme.background)
+		g.drawStars(screen)
+	}
 
 	if g.mode == ModePlay {
-		g.drawConcurrencyRadar(screen)
+		g.drawWorldBounds(screen)
 		g.drawShip(screen)
 		g.drawAstroids(screen)
 		g.drawMiniAstroids(screen)
 		g.drawRocket(screen)
-		updateStars(g, g.shipXPos, g.shipYPos)
+		g.particles.draw(screen, g.camera.geoM())
+		g.drawThreatArrows(screen)
+		g.drawConcurrencyRadar(screen)
+		g.drawGameTypeHUD(screen)
+		g.drawDifficultyHUD(screen)
+		g.drawMinimap(screen)
+		g.drawTouchControls(screen)
+		if followed := g.followedPlayers(); len(followed) > 0 {
+			x, y := g.camera.worldToScreen(followed[0].shipXPos, followed[0].shipYPos)
+			updateStars(g, x, y)
+		}
 	}
 
 	if g.mode == ModeStart {
 		g.drawStartScreen(screen)
-		updateStars(g, float64(windowWidth/2), float64(windowHeight/2))
+		updateStars(g, float64(screenWidth/2), float64(screenHeight/2))
+	}
+
+	if g.mode == ModeLobby {
+		g.drawLobby(screen)
+		updateStars(g, float64(screenWidth/2), float64(screenHeight/2))
+	}
+
+	if g.mode == ModeAwards {
+		g.drawAchievements(screen)
+		updateStars(g, float64(screenWidth/2), float64(screenHeight/2))
+	}
+
+	if g.mode == ModeStats {
+		g.drawStats(screen)
+		updateStars(g, float64(screenWidth/2), float64(screenHeight/2))
+	}
+
+	if _, ok := settingsTitles[g.mode]; ok {
+		g.drawSettings(screen)
+		updateStars(g, float64(screenWidth/2), float64(screenHeight/2))
 	}
 
 	if g.mode == ModeLevels {
 		g.drawLevels(screen)
-		updateStars(g, float64(windowWidth), float64(windowHeight/2))
+		updateStars(g, float64(screenWidth), float64(screenHeight/2))
 	}
 
 	if g.mode == ModePause {
@@ -590,94 +715,98 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	if g.mode == ModeWon {
 		g.drawGameWonScreen(screen)
 	}
+
+	if g.mode == ModeResult {
+		g.drawVersusResults(screen)
+	}
+
+	g.drawNotifications(screen)
+	g.drawNetStatus(screen)
 }
 
 func (g *Game) drawConcurrencyRadar(screen *ebiten.Image) {
 
+	if g.config.RadarDetail == RadarOff {
+		g.drawPlayerHealth(screen)
+		return
+	}
+
 	drawOptions := &ebiten.DrawImageOptions{}
 
 	drawOptions.GeoM.Translate(0, 10)
 	screen.DrawImage(g.gameConcurrencyRadar, drawOptions)
 
-	drawOptions.GeoM.Translate(0, 550)
-	screen.DrawImage(g.gamePlayerHealth, drawOptions)
+	g.drawPlayerHealth(screen)
 
-	health := fmt.Sprintf("%d", g.playerHealth)
-	asteroids := fmt.Sprintf("Number of Asteroids (Go Routines): %d", AsteroidsInGame)
-	minAsteroids := fmt.Sprintf("Number of Mini-Asteroids (Sub Go Routines): %d", miniAsteroidsInGame)
-	genThreads := fmt.Sprintf("Go routines used to generate Asteroids: %d", generationGoroutines)
-	updateThreads := fmt.Sprintf("Go routines used to update Asteroids: %d", updateGoroutines)
+	asteroids := tr("radar.asteroids", AsteroidsInGame)
+	minAsteroids := tr("radar.mini_asteroids", miniAsteroidsInGame)
+	genThreads := tr("radar.generation", generationGoroutines)
+	updateThreads := tr("radar.update", updateGoroutines)
 
-	ebitenutil.DebugPrintAt(screen, health, 210, 572)
-	ebitenutil.DebugPrintAt(screen, asteroids, 30, 50)
-	ebitenutil.DebugPrintAt(screen, minAsteroids, 30, 70)
-	ebitenutil.DebugPrintAt(screen, genThreads, 30, 90)
-	ebitenutil.DebugPrintAt(screen, updateThreads, 30, 110)
+	g.print(screen, asteroids, 30, 50)
+	g.print(screen, minAsteroids, 30, 70)
+	if g.config.RadarDetail == RadarBasic {
+		return
+	}
+	g.print(screen, genThreads, 30, 90)
+	g.print(screen, updateThreads, 30, 110)
 
+	// Clients are shown what the host sends, which does not include the host's strategy
+	if g.client == nil {
+		g.print(screen, tr("radar.strategy", g.strategy), 30, 130)
+	}
 }
 
 func (g *Game) drawShip(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	drawOptions.GeoM.Translate(g.shipXPos, g.shipYPos)
-	screen.DrawImage(g.ship, drawOptions)
+	for _, p := range g.players {
+		if !p.alive() {
+			continue
+		}
+		// Blink while invulnerable after respawning, or fade without flashing
+		drawOptions := &ebiten.DrawImageOptions{}
+		if p.invulnerable > 0 && g.config.ReduceFlashing {
+			drawOptions.ColorM.Scale(1, 1, 1, 0.5)
+		} else if p.invulnerable > 0 && (p.invulnerable/8)%2 == 0 {
+			continue
+		}
+		drawOptions.GeoM.Translate(p.shipXPos, p.shipYPos)
+		drawOptions.GeoM.Concat(g.camera.geoM())
+		// Tint the other players' ships to tell them apart
+		if tint, ok := playerTints[p.id]; ok {
+			drawOptions.ColorM.Scale(tint[0], tint[1], tint[2], 1)
+		}
+		g.drawSprite(screen, g.ship, drawOptions)
+	}
 }
 
 func (g *Game) drawRocket(screen *ebiten.Image) {
-	drawOptions3 := &ebiten.DrawImageOptions{}
-	drawOptions3.GeoM.Translate(g.rocketXPos, g.rocketYPos)
-	screen.DrawImage(g.rocket, drawOptions3)
-}
-
-func (g *Game) drawStartScreen(screen *ebiten.Image) {
-	g.drawLogo(screen)
-	g.drawInstructions(screen)
+	for _, p := range g.players {
+		if !p.alive() {
+			continue
+		}
+		drawOptions3 := &ebiten.DrawImageOptions{}
+		drawOptions3.GeoM.Translate(p.rocketXPos, p.rocketYPos)
+		drawOptions3.GeoM.Concat(g.camera.geoM())
+		g.drawSprite(screen, g.rocket, drawOptions3)
+	}
 }
 
 func (g *Game) drawLogo(screen *ebiten.Image) {
 	drawOptions := &ebiten.DrawImageOptions{}
 	x, y := g.gameLogo.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y))
+	drawOptions.GeoM.Translate(float64(screenWidth/2-x/2), float64(screenHeight/2-y))
 	screen.DrawImage(g.gameLogo, drawOptions)
 }
 
-func (g *Game) drawInstructions(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	x, y := g.gameInstructions.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y/8))
-	screen.DrawImage(g.gameInstructions, drawOptions)
-}
-
-func (g *Game) drawLevels(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	x, y := g.gameLevels.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y/2))
-	screen.DrawImage(g.gameLevels, drawOptions)
-}
-
-func (g *Game) drawGameOverScreen(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	x, y := g.gameOver.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y/2))
-	screen.DrawImage(g.gameOver, drawOptions)
-}
-
-func (g *Game) drawGamePausedScreen(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	x, y := g.gamePaused.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y/2))
-	screen.DrawImage(g.gamePaused, drawOptions)
-}
-
-func (g *Game) drawGameWonScreen(screen *ebiten.Image) {
-	drawOptions := &ebiten.DrawImageOptions{}
-	x, y := g.gameWon.Size()
-	drawOptions.GeoM.Translate((windowWidth/2)-float64(x/2), (windowHeight/2)-float64(y/2))
-	screen.DrawImage(g.gameWon, drawOptions)
-}
-
 func (g *Game) drawAstroids(screen *ebiten.Image) {
 
+	if g.asteroidStyle != StyleSprite {
+		g.drawVectorAsteroids(screen, g.asteroids.asteroidsList, AsteroidsInGame, asteroidWidth, asteroidHeight)
+		return
+	}
+
 	w, h := g.asteroidImage.Size()
+	camera := g.camera.geoM()
 
 	for i := 0; i < AsteroidsInGame; i++ {
 
@@ -687,13 +816,21 @@ func (g *Game) drawAstroids(screen *ebiten.Image) {
 		g.drawOps.GeoM.Rotate(2 * math.Pi * float64(s.angle) / maxAngle)
 		g.drawOps.GeoM.Translate(float64(w)/2, float64(h)/2)
 		g.drawOps.GeoM.Translate(float64(s.x), float64(s.y))
-		screen.DrawImage(g.asteroidImage, &g.drawOps)
+		g.drawOps.GeoM.Concat(camera)
+		g.drawSprite(screen, g.asteroidImage, &g.drawOps)
 
 	}
 }
 
 func (g *Game) drawMiniAstroids(screen *ebiten.Image) {
 
+	if g.asteroidStyle != StyleSprite {
+		g.drawVectorAsteroids(screen, g.miniAsteroids.asteroidsList, miniAsteroidsInGame, miniAsteroidWidth, miniAsteroidHeight)
+		return
+	}
+
+	camera := g.camera.geoM()
+
 	for i := 0; i < miniAsteroidsInGame; i++ {
 
 		s := g.miniAsteroids.asteroidsList[i]
@@ -702,7 +839,8 @@ func (g *Game) drawMiniAstroids(screen *ebiten.Image) {
 		g.drawOps.GeoM.Rotate(2 * math.Pi * float64(s.angle) / maxAngle)
 		g.drawOps.GeoM.Translate(float64(miniAsteroidWidth)/2, float64(miniAsteroidHeight)/2)
 		g.drawOps.GeoM.Translate(float64(s.x), float64(s.y))
-		screen.DrawImage(g.miniAsteroidImage, &g.drawOps)
+		g.drawOps.GeoM.Concat(camera)
+		g.drawSprite(screen, g.miniAsteroidImage, &g.drawOps)
 
 	}
 }
@@ -710,9 +848,9 @@ func (g *Game) drawMiniAstroids(screen *ebiten.Image) {
 // Stars Background Functions
 // Initialise stars
 func (s *Star) Init() {
-	s.tox = rand.Float64() * windowWidth * 64
+	s.tox = rand.Float64() * float64(screenWidth) * 64
 	s.fromx = s.tox
-	s.toy = rand.Float64() * windowHeight * 64
+	s.toy = rand.Float64() * float64(screenHeight) * 64
 	s.fromy = s.toy
 	s.brightness = rand.Float64() * 0xff
 }
@@ -727,7 +865,7 @@ func (s *Star) Update(x, y float64) {
 	if 0xff < s.brightness {
 		s.brightness = 0xff
 	}
-	if s.fromx < 0 || windowWidth*64 < s.fromx || s.fromy < 0 || windowHeight*64 < s.fromy {
+	if s.fromx < 0 || float64(screenWidth*64) < s.fromx || s.fromy < 0 || float64(screenHeight*64) < s.fromy {
 		s.Init()
 	}
 }
@@ -735,15 +873,15 @@ func (s *Star) Update(x, y float64) {
 // Calls .Draw() for 1024 stars
 func (g *Game) drawStars(screen *ebiten.Image) {
 	for i := 0; i < 1024; i++ {
-		g.stars[i].Draw(screen)
+		g.stars[i].Draw(screen, g.theme.stars)
 	}
 }
 
-// Draws each individual star
-func (s *Star) Draw(screen *ebiten.Image) {
-	c := color.RGBA{R: uint8(0xbb * s.brightness / 0xff),
-		G: uint8(0xdd * s.brightness / 0xff),
-		B: uint8(0xff * s.brightness / 0xff),
+// Draws each individual star in the theme's star colour
+func (s *Star) Draw(screen *ebiten.Image, tint color.RGBA) {
+	c := color.RGBA{R: uint8(float64(tint.R) * s.brightness / 0xff),
+		G: uint8(float64(tint.G) * s.brightness / 0xff),
+		B: uint8(float64(tint.B) * s.brightness / 0xff),
 		A: 0xff}
 	ebitenutil.DrawLine(screen, s.fromx/64, s.fromy/64, s.tox/64, s.toy/64, c)
 }
@@ -755,102 +893,136 @@ func updateStars(g *Game, x, y float64) {
 	}
 }
 
-// returns display layout
-func (g *Game) Layout(outsideWidth, outsideHeight int) (windowWidth, windowHeight int) {
-	return 800, 600
+// returns display layout, the logical screen ebiten scales to fit the window
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	screenWidth, screenHeight = g.config.screenSize(outsideWidth, outsideHeight)
+	return screenWidth, screenHeight
 }
 
 func loadAssets(g *Game) {
-	shipIcon, _, err := ebitenutil.NewImageFromFile("GUI/GameAssets/ship.png")
-	if err != nil {
-		log.Fatalf("Error Loading Ship Icon: %v", err)
-	}
+	assets := g.assets
 
-	logo, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameLogo.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Logo: %v", err)
-	}
+	g.ship = assets.image("GUI/GameAssets/ship.png", tr("asset.ship"))
+	g.asteroidImage = assets.image("GUI/GameAssets/asteroid.png", tr("asset.asteroid"))
+	g.miniAsteroidImage = assets.image("GUI/GameAssets/miniAsteroid.png", tr("asset.mini_asteroid"))
 
-	instructions, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameInstructions.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Instructions: %v", err)
-	}
+	g.gameLogo = assets.image("GUI/GameScreens/gameLogo.png", tr("asset.logo"))
+	g.gameConcurrencyRadar = assets.image("GUI/GameScreens/gameConcurrencyRadar.png", tr("asset.radar"))
+	g.gamePlayerHealth = assets.image("GUI/GameScreens/gamePlayerHealth.png", tr("asset.health"))
 
-	levels, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameLevels.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Levels: %v", err)
-	}
+	rocketIcon := ebiten.NewImage(2, 10)
+	rocketIcon.Fill(g.theme.rocket)
+	g.rocket = rocketIcon
 
-	gameOvers, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameOver.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Game Over Screen: %v", err)
-	}
+	assets.summary()
+}
 
-	gamePaused, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gamePaused.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Game Paused Screen: %v", err)
+// Main Function
+func main() {
+	host := flag.String("host", "", "host a network game on this address, e.g. :"+defaultNetPort)
+	join := flag.String("join", "", "join the network game hosted at this address, e.g. 192.168.1.10:"+defaultNetPort)
+	name := flag.String("name", "Player", "name shown to the host when joining a network game")
+	stream := flag.String("stream", "", "stream the live game state to spectators on this address, e.g. localhost:"+defaultStreamPort)
+	spectate := flag.String("spectate", "", "watch the game streamed from this address, e.g. 192.168.1.10:"+defaultStreamPort)
+	noAudio := flag.Bool("noaudio", false, "run without sound, for machines with no sound device")
+	assetsDir := flag.String("assets", "", "folder of images that replace the built in ones, laid out like the GUI folder")
+	checkLocales := flag.Bool("checklocales", false, "check every language has all of the game's text, then exit")
+	script := flag.String("script", "", "play the keys held on each tick from this file before handing over to the keyboard")
+	flag.Parse()
+
+	// Read the text first, so messages printed while loading are in the system's language
+	// until the saved choice is known
+	loadCatalogs()
+	setLocale(systemLocale())
+
+	if *checkLocales {
+		problems := checkCatalogs(loadFonts())
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		fmt.Printf("%s \n", tr("console.locales_ok", len(catalogs)))
+		return
 	}
 
-	gameWon, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameWon.png")
-	if err != nil {
-		log.Fatalf("Error Loading Go Asteroids Won Screen: %v", err)
-	}
+	ebiten.SetWindowSize(baseWidth, baseHeight)
+	ebiten.SetWindowResizable(true)
+	ebiten.SetWindowTitle("Go Asteroids")
 
-	asteroidIcon, _, err := ebitenutil.NewImageFromFile("GUI/GameAssets/asteroid.png")
-	if err != nil {
-		log.Fatalf("Error Loading Asteroid Icon: %v", err)
+	g := &Game{playerName: *name, assets: newAssets(*assetsDir), menus: newMenus(), fonts: loadFonts(), input: keyboardInput{}}
+	g.lobby.browser, g.lobby.hosting, g.lobby.joined = newLobbyMenus()
+	if *script != "" {
+		f, err := os.Open(*script)
+		if err != nil {
+			log.Fatal(tr("console.error_input_script", err))
+		}
+		s, err := ReadInputScript(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(tr("console.error_input_script", err))
+		}
+		g.input = s
 	}
+	g.profile = loadProfile()
+	g.config = loadConfig()
+	g.loadLocale()
 
-	miniAsteroidIcon, _, err := ebitenutil.NewImageFromFile("GUI/GameAssets/miniAsteroid.png")
-	if err != nil {
-		log.Fatalf("Error Loading Mini-Asteroid Icon: %v", err)
-	}
+	fmt.Println(tr("console.welcome"))
+	fmt.Println(tr("console.goroutines_printed"))
 
-	radarLogo, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gameConcurrencyRadar.png")
-	if err != nil {
-		log.Fatalf("Error Loading Concurrency Radar Logo: %v", err)
-	}
+	g.audio = newAudio(!*noAudio)
+	g.applyConfig()
+	g.loadTheme()
 
-	HealthBackground, _, err := ebitenutil.NewImageFromFile("GUI/GameScreens/gamePlayerHealth.png")
-	if err != nil {
-		log.Fatalf("Error Loading Player Health Icon: %v", err)
+	if *host != "" {
+		h, err := NewNetHost(*host, *name)
+		if err != nil {
+			log.Fatal(tr("console.error_hosting", err))
+		}
+		if err := h.StartDiscovery(); err != nil {
+			fmt.Printf("%s \n", tr("console.error_discovery", err))
+		}
+		defer h.Close()
+		g.host = h
+		fmt.Printf("%s \n", tr("console.hosting", h.Addr()))
+	} else if *join != "" {
+		c, err := DialNetClient(*join, *name)
+		if err != nil {
+			log.Fatal(tr("console.error_joining", err))
+		}
+		defer c.Close()
+		g.client = c
+		fmt.Printf("%s \n", tr("console.joined", *join, c.PlayerID()))
+	} else if *spectate != "" {
+		c, err := DialSpectator(*spectate)
+		if err != nil {
+			log.Fatal(tr("console.error_watching", err))
+		}
+		defer c.Close()
+		g.client = c
+		ebiten.SetWindowTitle(tr("window.spectating", *spectate))
+		fmt.Printf("%s \n", tr("console.watching", *spectate))
 	}
 
-	g.ship = shipIcon
-	g.asteroidImage = asteroidIcon
-	g.miniAsteroidImage = miniAsteroidIcon
-
-	g.gameLogo = logo
-	g.gameInstructions = instructions
-	g.gameConcurrencyRadar = radarLogo
-	g.gamePlayerHealth = HealthBackground
-
-	g.gameOver = gameOvers
-	g.gamePaused = gamePaused
-	g.gameWon = gameWon
-	g.gameLevels = levels
-
-	rocketIcon := ebiten.NewImage(2, 10)
-	rocketIcon.Fill(color.White)
-	g.rocket = rocketIcon
-
-}
-
-// Main Function
-func main() {
-	ebiten.SetWindowSize(windowWidth, windowHeight)
-	ebiten.SetWindowTitle("Go Asteroids")
-
-	fmt.Println("Welcome To Go Asteroids")
-	fmt.Println("Go Routines will be printed here")
-
-	g := &Game{}
-	loadAssets(g)
+	if *stream != "" && *spectate == "" {
+		s, err := NewSpectatorServer(*stream)
+		if err != nil {
+			log.Fatal(tr("console.error_streaming", err))
+		}
+		defer s.Close()
+		g.stream = s
+		fmt.Printf("%s \n", tr("console.streaming", s.Addr()))
+	}
 
 	g.mode = ModeStart
-	g.shooting = false
 	if err := ebiten.RunGame(g); err != nil {
-		log.Fatalf("Error Running Game: %v", err)
+		if err == errHostDisconnected {
+			fmt.Println(tr("console.host_left"))
+			return
+		}
+		log.Fatal(tr("console.error_running", err))
 	}
 
 }
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// Loads the text of the game, so messages printed during the tests read as they do in play
+func TestMain(m *testing.M) {
+	loadCatalogs()
+	os.Exit(m.Run())
+}
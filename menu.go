@@ -0,0 +1,423 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// Height of each menu line in pixels
+	menuLineHeight = 32
+
+	// Space kept below a menu for the help lines, a longer menu scrolls
+	menuBottomMargin = 70
+)
+
+// From the palette in use, and brighter hints in high contrast mode (see useColours)
+var (
+	colourLost = palettes[PaletteStandard].lost
+	colourWon  = palettes[PaletteStandard].won
+	colourHint = standardHint
+)
+
+// MenuItem is one line of a menu, either something to do or an option with a value to change
+type MenuItem struct {
+	// Text of the item, worked out each time it is drawn so it can show scores and settings
+	label func(g *Game) string
+
+	// Keys that choose the item straight away, shown next to it
+	keys []ebiten.Key
+
+	// Called when the item is chosen
+	action func(g *Game)
+
+	// Called with -1 or 1 when Left or Right is pressed on an option, and with 1 when it is chosen
+	change func(g *Game, by int)
+
+	// Hides the item when it does not apply, e.g. friendly fire in a one player game
+	hidden func(g *Game) bool
+}
+
+// Menu is a list of items navigated with the arrow keys or a gamepad's d-pad
+type Menu struct {
+	items    []MenuItem
+	selected int
+
+	// Called when Escape or the gamepad's back button is pressed
+	back func(g *Game)
+
+	// Where the first line was last drawn, to find the item under the mouse
+	top int
+
+	// First item and number of items drawn last, when the menu is too long for the screen
+	first, shown int
+}
+
+// Menu buttons pressed this tick, from the keyboard or any gamepad
+type MenuInput struct {
+	up, down, left, right bool
+	confirm, back         bool
+}
+
+// Reads the menu buttons, with edge detection so holding a key moves one line at a time.
+// The arrow keys always move around menus, so a remapped keyboard can not get stuck.
+func (g *Game) readMenuInput() MenuInput {
+	in := MenuInput{
+		up:      g.input.JustPressed(ebiten.KeyUp),
+		down:    g.input.JustPressed(ebiten.KeyDown),
+		left:    g.input.JustPressed(ebiten.KeyLeft),
+		right:   g.input.JustPressed(ebiten.KeyRight),
+		confirm: g.actionPressed(ControlsSolo, ActionConfirm),
+		back:    g.actionPressed(ControlsSolo, ActionBack),
+	}
+	for _, id := range g.pads.connected {
+		pressed := func(b ebiten.StandardGamepadButton) bool {
+			return inpututil.IsStandardGamepadButtonJustPressed(id, b)
+		}
+		in.up = in.up || pressed(ebiten.StandardGamepadButtonLeftTop)
+		in.down = in.down || pressed(ebiten.StandardGamepadButtonLeftBottom)
+		in.left = in.left || pressed(ebiten.StandardGamepadButtonLeftLeft)
+		in.right = in.right || pressed(ebiten.StandardGamepadButtonLeftRight)
+	}
+	return in
+}
+
+// Returns the items shown, skipping hidden ones
+func (m *Menu) visible(g *Game) []*MenuItem {
+	var items []*MenuItem
+	for i := range m.items {
+		if m.items[i].hidden == nil || !m.items[i].hidden(g) {
+			items = append(items, &m.items[i])
+		}
+	}
+	return items
+}
+
+// Moves the selection and chooses items, once a tick
+func (m *Menu) update(g *Game) {
+
+	items := m.visible(g)
+	if len(items) == 0 {
+		return
+	}
+	if m.selected >= len(items) {
+		m.selected = len(items) - 1
+	}
+	in := g.readMenuInput()
+
+	if in.up {
+		m.selected = (m.selected + len(items) - 1) % len(items)
+		g.audio.play(SoundMenu)
+	}
+	if in.down {
+		m.selected = (m.selected + 1) % len(items)
+		g.audio.play(SoundMenu)
+	}
+
+	item := items[m.selected]
+	if item.change != nil && (in.left || in.right) {
+		if in.left {
+			item.change(g, -1)
+		} else {
+			item.change(g, 1)
+		}
+		g.audio.play(SoundMenu)
+	}
+	if in.confirm {
+		m.choose(g, item)
+		return
+	}
+	if in.back && m.back != nil {
+		m.back(g)
+		return
+	}
+
+	for i, it := range items {
+		for _, k := range it.keys {
+			if g.input.JustPressed(k) {
+				m.selected = i
+				m.choose(g, it)
+				return
+			}
+		}
+	}
+
+	// Moving the mouse over an item selects it, and clicking or tapping chooses it. Options
+	// change down when the left half of the line is pressed and up for the right half.
+	if i, ok := m.itemAt(g.pointer.mouseX, g.pointer.mouseY, len(items)); ok && g.pointer.mouseMoved {
+		m.selected = i
+	}
+	if x, y, ok := g.pointer.press(); ok {
+		if i, ok := m.itemAt(x, y, len(items)); ok {
+			m.selected = i
+			if it := items[i]; it.change != nil && x < screenWidth/2 {
+				it.change(g, -1)
+				g.audio.play(SoundMenu)
+			} else {
+				m.choose(g, it)
+			}
+		}
+	}
+}
+
+func (m *Menu) choose(g *Game, item *MenuItem) {
+	if item.change != nil {
+		item.change(g, 1)
+		g.audio.play(SoundMenu)
+	}
+	if item.action != nil {
+		item.action(g)
+	}
+}
+
+// Draws the menu centred across the screen with its first line at y, the selected line highlighted
+func (m *Menu) draw(g *Game, screen *ebiten.Image, y int) {
+
+	m.top = y
+
+	// Scroll to keep the selected item on the screen
+	items := m.visible(g)
+	m.shown = len(items)
+	if fit := (fromBottom(menuBottomMargin) - y) / menuLineHeight; fit < m.shown {
+		m.shown = clampInt(fit, 1, m.shown)
+	}
+	if m.selected < m.first {
+		m.first = m.selected
+	}
+	if m.selected >= m.first+m.shown {
+		m.first = m.selected - m.shown + 1
+	}
+	if m.first > len(items)-m.shown {
+		m.first = len(items) - m.shown
+	}
+
+	for i := m.first; i < m.first+m.shown; i++ {
+		item := items[i]
+		label := item.label(g)
+		c := g.theme.text
+		if i == m.selected {
+			c = g.theme.highlight
+			if item.change != nil {
+				label = "<  " + label + "  >"
+			} else {
+				label = ">  " + label + "  <"
+			}
+		}
+
+		lineY := y + (i-m.first)*menuLineHeight
+		drawCentredText(screen, label, g.fonts.body, lineY, c)
+		// Shortcut keys are only shown to someone using the keyboard
+		if len(item.keys) > 0 && g.device == DeviceKeyboard {
+			x := (screenWidth+textWidth(g.fonts.body, label))/2 + 12
+			drawText(screen, "["+keyName(item.keys[0])+"]", g.fonts.small, x, lineY+4, colourHint)
+		}
+	}
+}
+
+// Name of a key as shown in menus
+func keyName(k ebiten.Key) string {
+	switch {
+	case k >= ebiten.KeyA && k <= ebiten.KeyZ:
+		return string(rune('A' + k - ebiten.KeyA))
+	case k >= ebiten.Key0 && k <= ebiten.Key9:
+		return string(rune('0' + k - ebiten.Key0))
+	}
+	return strings.TrimPrefix(k.String(), "Arrow")
+}
+
+// Builds the menus for each screen
+func newMenus() map[Mode]*Menu {
+
+	toStart := func(g *Game) { g.mode = ModeStart }
+	toLevels := func(g *Game) { g.mode = ModeLevels }
+	quit := MenuItem{label: fixedLabel("menu.quit"), keys: []ebiten.Key{ebiten.KeyQ}, action: func(g *Game) {
+		fmt.Println(tr("console.thanks"))
+		os.Exit(1)
+	}}
+
+	levels := &Menu{back: toStart}
+	for level := 1; level <= len(levelAsteroids); level++ {
+		level := level
+		levels.items = append(levels.items, MenuItem{
+			label:  func(g *Game) string { return g.levelLabel(level) },
+			keys:   []ebiten.Key{ebiten.Key1 + ebiten.Key(level-1)},
+			action: func(g *Game) { g.startLevel(level) },
+		})
+	}
+	levels.items = append(levels.items,
+		MenuItem{
+			label: func(g *Game) string { return tr("menu.game_mode", g.gameType) },
+			keys:  []ebiten.Key{ebiten.KeyM},
+			change: func(g *Game, by int) {
+				g.gameType = (g.gameType + gameTypeCount + GameType(by)) % gameTypeCount
+			},
+		},
+		MenuItem{
+			label:  func(g *Game) string { return tr("menu.players", g.playersLabel()) },
+			keys:   []ebiten.Key{ebiten.KeyC},
+			change: func(g *Game, by int) { g.coop = !g.coop },
+			hidden: func(g *Game) bool { return g.gameType == TypeVersus },
+		},
+		MenuItem{
+			label:  func(g *Game) string { return tr("menu.friendly_fire", onOff(g.friendlyFire)) },
+			keys:   []ebiten.Key{ebiten.KeyF},
+			change: func(g *Game, by int) { g.friendlyFire = !g.friendlyFire },
+			hidden: func(g *Game) bool { return !g.coop || g.gameType == TypeVersus },
+		},
+		MenuItem{
+			label: func(g *Game) string { return tr("menu.adaptive", onOff(g.adaptive)) },
+			keys:  []ebiten.Key{ebiten.KeyD},
+			change: func(g *Game, by int) {
+				g.config.Adaptive = !g.config.Adaptive
+				g.configChanged()
+			},
+		},
+		MenuItem{
+			label: func(g *Game) string { return tr("menu.asteroid_style", g.asteroidStyle) },
+			keys:  []ebiten.Key{ebiten.KeyV},
+			change: func(g *Game, by int) {
+				g.config.AsteroidStyle = (g.config.AsteroidStyle + asteroidStyleCount + AsteroidStyle(by)) % asteroidStyleCount
+				g.configChanged()
+			},
+		},
+		MenuItem{label: fixedLabel("menu.back"), keys: []ebiten.Key{ebiten.KeyB}, action: toStart},
+	)
+
+	menus := map[Mode]*Menu{
+		ModeStart: {items: []MenuItem{
+			{label: fixedLabel("menu.play"), keys: []ebiten.Key{ebiten.KeySpace}, action: toLevels},
+			{label: fixedLabel("menu.lobby"), keys: []ebiten.Key{ebiten.KeyL}, action: func(g *Game) { g.enterLobby() }},
+			{label: fixedLabel("menu.achievements"), keys: []ebiten.Key{ebiten.KeyA}, action: func(g *Game) { g.mode = ModeAwards }},
+			{label: fixedLabel("menu.statistics"), keys: []ebiten.Key{ebiten.KeyS}, action: func(g *Game) { g.mode = ModeStats }},
+			{label: fixedLabel("menu.settings"), keys: []ebiten.Key{ebiten.KeyO}, action: func(g *Game) { g.openSettings() }},
+			quit,
+		}},
+		ModeLevels: levels,
+		ModePause: {back: func(g *Game) { g.mode = ModePlay }, items: []MenuItem{
+			{label: fixedLabel("menu.resume"), keys: []ebiten.Key{ebiten.KeyR}, action: func(g *Game) { g.mode = ModePlay }},
+			{label: fixedLabel("menu.settings"), keys: []ebiten.Key{ebiten.KeyO}, action: func(g *Game) { g.openSettings() }},
+			{label: fixedLabel("menu.main_menu"), keys: []ebiten.Key{ebiten.KeyM}, action: func(g *Game) {
+				g.inited = false
+				g.mode = ModeStart
+			}},
+			quit,
+		}},
+		ModeOver: {back: toStart, items: []MenuItem{
+			{label: fixedLabel("menu.play_again"), keys: []ebiten.Key{ebiten.KeyR}, action: func(g *Game) {
+				g.miniAsteroids.asteroidsList = g.miniAsteroids.asteroidsList[:0]
+				g.mode = ModeLevels
+			}},
+			{label: fixedLabel("menu.main_menu"), keys: []ebiten.Key{ebiten.KeyP}, action: toStart},
+			quit,
+		}},
+		ModeWon: {back: toStart, items: []MenuItem{
+			{label: fixedLabel("menu.play_again"), keys: []ebiten.Key{ebiten.KeyR}, action: toLevels},
+			{label: fixedLabel("menu.main_menu"), keys: []ebiten.Key{ebiten.KeyP}, action: toStart},
+			quit,
+		}},
+		ModeResult: {back: toStart, items: []MenuItem{
+			{label: fixedLabel("menu.rematch"), keys: []ebiten.Key{ebiten.KeyR}, action: toLevels},
+			{label: fixedLabel("menu.main_menu"), keys: []ebiten.Key{ebiten.KeyP}, action: toStart},
+			quit,
+		}},
+	}
+	for mode, m := range newSettingsMenus() {
+		menus[mode] = m
+	}
+	return menus
+}
+
+// Label that only changes with the language
+func fixedLabel(key string) func(g *Game) string {
+	return func(g *Game) string { return tr(key) }
+}
+
+func onOff(on bool) string {
+	if on {
+		return tr("option.on")
+	}
+	return tr("option.off")
+}
+
+// Starts a level from the level screen
+func (g *Game) startLevel(level int) {
+	if g.inited {
+		return
+	}
+	g.level = level
+	g.init(levelAsteroids[level])
+	g.mode = ModePlay
+}
+
+// Level menu line with the number of asteroids, best score and par time
+func (g *Game) levelLabel(level int) string {
+
+	label := tr("menu.level", level, levelAsteroids[level])
+	if g.gameType == TypeVersus {
+		return label
+	}
+
+	best := "--"
+	if scores := g.profile.highScores(g.gameType, level, g.playerCount()); len(scores) > 0 {
+		best = g.gameType.formatScore(scores[0].Score)
+	}
+	label += "   " + tr("menu.level_best", best)
+	if g.gameType == TypeTimeAttack {
+		label += "   " + tr("menu.level_par", levelParTimes[level])
+	}
+	return label
+}
+
+func (g *Game) playersLabel() string {
+	if g.gameType == TypeVersus {
+		return tr("menu.players_versus", versusRoundsToWin)
+	}
+	if g.coop {
+		return tr("menu.players_coop")
+	}
+	return tr("menu.players_one")
+}
+
+// Start screen - the logo, the main menu and the controls
+func (g *Game) drawStartScreen(screen *ebiten.Image) {
+	g.drawLogo(screen)
+	g.menus[ModeStart].draw(g, screen, 310)
+	keys := &g.config.Keys[ControlsSolo]
+	controls := g.prompt(
+		tr("start.controls", keys.moveLabel(), keys.label(ActionFire), keys.label(ActionPause)),
+		tr("start.controls", tr("pad.move"), padLabel(ActionFire), padLabel(ActionPause)),
+		tr("pointer.controls"),
+	)
+	drawCentredText(screen, controls, g.fonts.small, fromBottom(70), colourHint)
+	help := g.prompt(tr("start.menu_help"), tr("pad.menu_help", padLabel(ActionConfirm), padLabel(ActionBack)), tr("pointer.menu_help"))
+	drawCentredText(screen, help, g.fonts.small, fromBottom(45), colourHint)
+}
+
+// Level screen - the levels and the options for the next game
+func (g *Game) drawLevels(screen *ebiten.Image) {
+	drawCentredText(screen, tr("levels.title"), g.fonts.heading, 50, g.theme.text)
+	drawCentredText(screen, g.gameType.description(), g.fonts.small, 90, colourHint)
+	g.menus[ModeLevels].draw(g, screen, 140)
+	drawCentredText(screen, tr("levels.help"), g.fonts.small, fromBottom(45), colourHint)
+}
+
+func (g *Game) drawGamePausedScreen(screen *ebiten.Image) {
+	drawCentredText(screen, tr("pause.title"), g.fonts.title, 170, g.theme.text)
+	g.menus[ModePause].draw(g, screen, 270)
+}
+
+func (g *Game) drawGameOverScreen(screen *ebiten.Image) {
+	drawCentredText(screen, tr("over.title"), g.fonts.title, 110, colourLost)
+	g.drawGameResult(screen)
+	g.menus[ModeOver].draw(g, screen, 370)
+}
+
+func (g *Game) drawGameWonScreen(screen *ebiten.Image) {
+	drawCentredText(screen, tr("won.title"), g.fonts.title, 110, colourWon)
+	g.drawGameResult(screen)
+	g.menus[ModeWon].draw(g, screen, 370)
+}
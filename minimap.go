@@ -0,0 +1,159 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// Position and scale of the minimap in the top right corner of the screen, its left edge
+	// measured from the right of the screen
+	minimapRight = 170
+	minimapY     = 85
+	minimapScale = 0.1
+
+	// Off screen asteroids closer than this to a ship, and flying towards it, get an arrow at the screen edge
+	threatRange = 700.0
+
+	// Gap between the arrows and the screen edge, and the arrow size
+	arrowInset = 24.0
+	arrowSize  = 12.0
+)
+
+// From the palette in use (see useColours)
+var (
+	minimapBackground = palettes[PaletteStandard].mapBackground
+	minimapEdge       = palettes[PaletteStandard].mapEdge
+	minimapView       = palettes[PaletteStandard].mapView
+	minimapAsteroid   = palettes[PaletteStandard].mapAsteroid
+	minimapMini       = palettes[PaletteStandard].mapMini
+	minimapShip       = palettes[PaletteStandard].ship
+	minimapAlly       = palettes[PaletteStandard].ally
+	minimapEnemy      = palettes[PaletteStandard].enemy
+	threatLarge       = palettes[PaletteStandard].threatLarge
+	threatMini        = palettes[PaletteStandard].threatMini
+)
+
+// Left edge of the minimap, which moves with the right of the screen
+func minimapX() float64 {
+	return float64(fromRight(minimapRight))
+}
+
+// Converts a point in the world to a point on the minimap
+func minimapPoint(x, y float64) (float64, float64) {
+	return minimapX() + x*minimapScale, minimapY + y*minimapScale
+}
+
+// Draws the whole world in miniature - the view, the ships and the asteroids by size
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+
+	w, h := worldWidth*minimapScale, worldHeight*minimapScale
+	ebitenutil.DrawRect(screen, minimapX(), minimapY, w, h, minimapBackground)
+	drawOutline(screen, minimapX(), minimapY, w, h, minimapEdge)
+
+	// Part of the world on screen
+	vx, vy := g.camera.screenToWorld(0, 0)
+	vx1, vy1 := g.camera.screenToWorld(float64(screenWidth), float64(screenHeight))
+	x0, y0 := minimapPoint(math.Max(vx, 0), math.Max(vy, 0))
+	x1, y1 := minimapPoint(math.Min(vx1, worldWidth), math.Min(vy1, worldHeight))
+	drawOutline(screen, x0, y0, x1-x0, y1-y0, minimapView)
+
+	for i := 0; i < AsteroidsInGame; i++ {
+		a := g.asteroids.asteroidsList[i]
+		x, y := minimapPoint(a.x+asteroidWidth/2, a.y+asteroidHeight/2)
+		ebitenutil.DrawRect(screen, x-2, y-2, 4, 4, minimapAsteroid)
+	}
+	for i := 0; i < miniAsteroidsInGame; i++ {
+		a := g.miniAsteroids.asteroidsList[i]
+		x, y := minimapPoint(a.x+miniAsteroidWidth/2, a.y+miniAsteroidHeight/2)
+		ebitenutil.DrawRect(screen, x-1, y-1, 2, 2, minimapMini)
+	}
+
+	followed := g.followedPlayers()
+	for _, p := range g.players {
+		if !p.alive() {
+			continue
+		}
+		c := minimapAlly
+		if g.gameType == TypeVersus {
+			c = minimapEnemy
+		}
+		for _, f := range followed {
+			if f == p {
+				c = minimapShip
+			}
+		}
+		x, y := minimapPoint(p.shipXPos+shipWidth/2, p.shipYPos+shipHeight/2)
+		ebitenutil.DrawRect(screen, x-2, y-3, 4, 6, c)
+	}
+}
+
+// Draws arrows at the edge of the screen towards nearby off screen asteroids heading for a ship
+func (g *Game) drawThreatArrows(screen *ebiten.Image) {
+
+	var followed []point
+	for _, p := range g.followedPlayers() {
+		followed = append(followed, point{p.shipXPos + shipWidth/2, p.shipYPos + shipHeight/2})
+	}
+
+	check := func(a *Asteroid, colour color.RGBA) {
+		centre := point{a.x + float64(a.width)/2, a.y + float64(a.height)/2}
+		sx, sy := g.camera.worldToScreen(centre.x, centre.y)
+		if sx >= 0 && sx <= float64(screenWidth) && sy >= 0 && sy <= float64(screenHeight) {
+			return
+		}
+		for _, ship := range followed {
+			d := centre.dist(ship)
+			// Heading towards the ship if its velocity points the same way as the line to the ship
+			if d > threatRange || a.vx*(ship.x-centre.x)+a.vy*(ship.y-centre.y) <= 0 {
+				continue
+			}
+			// Closer asteroids get bigger arrows
+			drawArrow(screen, sx, sy, arrowSize*(1.5-d/threatRange), colour)
+			return
+		}
+	}
+
+	for i := 0; i < AsteroidsInGame; i++ {
+		check(g.asteroids.asteroidsList[i], threatLarge)
+	}
+	for i := 0; i < miniAsteroidsInGame; i++ {
+		check(g.miniAsteroids.asteroidsList[i], threatMini)
+	}
+}
+
+// Draws an arrow on the screen edge, on the line from the screen centre to a point off screen, pointing at it
+func drawArrow(screen *ebiten.Image, x, y, size float64, colour color.RGBA) {
+
+	cx, cy := float64(screenWidth)/2, float64(screenHeight)/2
+	dx, dy := x-cx, y-cy
+	angle := math.Atan2(dy, dx)
+
+	// Scale the direction so the arrow sits just inside the nearest screen edge
+	halfW, halfH := cx-arrowInset, cy-arrowInset
+	scale := math.Min(halfW/math.Abs(dx), halfH/math.Abs(dy))
+	ax, ay := cx+dx*scale, cy+dy*scale
+
+	tipX, tipY := ax+math.Cos(angle)*size, ay+math.Sin(angle)*size
+	leftX, leftY := ax+math.Cos(angle+2.5)*size, ay+math.Sin(angle+2.5)*size
+	rightX, rightY := ax+math.Cos(angle-2.5)*size, ay+math.Sin(angle-2.5)*size
+
+	r, g, b, a := float32(colour.R)/0xff, float32(colour.G)/0xff, float32(colour.B)/0xff, float32(colour.A)/0xff
+	vertices := []ebiten.Vertex{
+		{DstX: float32(tipX), DstY: float32(tipY), ColorR: r, ColorG: g, ColorB: b, ColorA: a},
+		{DstX: float32(leftX), DstY: float32(leftY), ColorR: r, ColorG: g, ColorB: b, ColorA: a},
+		{DstX: float32(rightX), DstY: float32(rightY), ColorR: r, ColorG: g, ColorB: b, ColorA: a},
+	}
+	screen.DrawTriangles(vertices, []uint16{0, 1, 2}, whitePixel(), nil)
+}
+
+// Draws the outline of a rectangle
+func drawOutline(screen *ebiten.Image, x, y, w, h float64, c color.Color) {
+	ebitenutil.DrawLine(screen, x, y, x+w, y, c)
+	ebitenutil.DrawLine(screen, x+w, y, x+w, y+h, c)
+	ebitenutil.DrawLine(screen, x+w, y+h, x, y+h, c)
+	ebitenutil.DrawLine(screen, x, y+h, x, y, c)
+}
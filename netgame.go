@@ -0,0 +1,377 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Clients draw other ships and asteroids this far in the past, between two snapshots
+const netInterpolationDelay = 100 * time.Millisecond
+
+// Client side prediction state, and the host's lobby while waiting to start
+type netClientView struct {
+	seq     uint32
+	pending []pendingInput
+	lobby   *LobbyState
+	ready   bool
+
+	// Goroutines running in the host, shown to spectators
+	goroutines int
+}
+
+// Input sent to the host that it has not acknowledged yet
+type pendingInput struct {
+	seq uint32
+	in  InputState
+}
+
+// Returns the ids of players connected over the network, if hosting
+func (g *Game) remotePlayerIDs() []int {
+	if g.host == nil {
+		return nil
+	}
+	return g.host.clientIDs()
+}
+
+// Adds and removes remote players as clients join and leave the host
+func (g *Game) updateHost() {
+
+	g.host.setSession(g.level, g.gameType, g.mode == ModeLobby)
+
+	joined, left := g.host.changes()
+	if !g.inited {
+		// Players are created when the next game starts
+		return
+	}
+
+	for _, id := range joined {
+		if g.gameType == TypeVersus {
+			// Versus is one on one, late joiners wait for the next match
+			continue
+		}
+		p := newPlayer(id, ControlsSolo)
+		p.remote = true
+		p.shipXPos = float64(worldWidth/2) - float64(shipWidth/2)
+		p.spawnXPos = p.shipXPos
+		p.resetRocket()
+		g.players = append(g.players, p)
+	}
+
+	for _, id := range left {
+		for i, p := range g.players {
+			if p.id != id || !p.remote {
+				continue
+			}
+			if g.gameType == TypeVersus {
+				// The match can not go on without an opponent
+				if g.mode == ModePlay || g.mode == ModePause {
+					g.finishGame(ModeResult)
+				}
+				break
+			}
+			g.players = append(g.players[:i], g.players[i+1:]...)
+			break
+		}
+	}
+}
+
+// Throws away the inputs of clients without a living ship - dead, waiting to respawn or left out
+// of a versus match - so they are not all applied in one tick when the ship comes back
+func (g *Game) discardIdleInputs() {
+	for _, id := range g.host.clientIDs() {
+		flying := false
+		for _, p := range g.players {
+			if p.remote && p.id == id && p.alive() {
+				flying = true
+			}
+		}
+		if !flying {
+			g.host.takeInputs(id)
+		}
+	}
+}
+
+// Builds a snapshot of the current game state
+func (g *Game) snapshot() Snapshot {
+
+	s := Snapshot{
+		Tick:          g.netTick,
+		Mode:          g.mode,
+		GameType:      g.gameType,
+		AsteroidStyle: g.asteroidStyle,
+		Level:         g.level,
+		Ticks:         g.ticks,
+		LastScore:     g.lastScore,
+		Round:         g.round,
+		RoundTicks:    g.roundTicks,
+		RoundWinner:   g.roundWinner,
+		Radar: RadarState{
+			Asteroids:            AsteroidsInGame,
+			MiniAsteroids:        miniAsteroidsInGame,
+			GenerationGoroutines: generationGoroutines,
+			UpdateGoroutines:     updateGoroutines,
+			Goroutines:           runtime.NumGoroutine(),
+		},
+	}
+
+	if g.mode == ModeLobby {
+		s.Lobby = g.lobbyState()
+	}
+
+	for _, p := range g.players {
+		s.Players = append(s.Players, PlayerState{
+			ID:           p.id,
+			X:            p.shipXPos,
+			Y:            p.shipYPos,
+			RocketX:      p.rocketXPos,
+			RocketY:      p.rocketYPos,
+			Shooting:     p.shooting,
+			Health:       p.health,
+			Score:        p.score,
+			Invulnerable: p.invulnerable,
+			RespawnTicks: p.respawnTicks,
+			RoundWins:    p.roundWins,
+			Eliminations: p.eliminations,
+		})
+	}
+	for i := 0; i < AsteroidsInGame; i++ {
+		a := g.asteroids.asteroidsList[i]
+		s.Asteroids = append(s.Asteroids, AsteroidState{ID: a.id, X: a.x, Y: a.y, VX: a.vx, VY: a.vy, Angle: a.angle})
+	}
+	for i := 0; i < miniAsteroidsInGame; i++ {
+		a := g.miniAsteroids.asteroidsList[i]
+		s.MiniAsteroids = append(s.MiniAsteroids, AsteroidState{ID: a.id, X: a.x, Y: a.y, VX: a.vx, VY: a.vy, Angle: a.angle})
+	}
+	return s
+}
+
+// Sends the state after this tick to every client and spectator
+func (g *Game) publishSnapshot() {
+	g.netTick++
+	s := g.snapshot()
+	if g.host != nil {
+		g.host.broadcast(s)
+	}
+	if g.stream != nil {
+		g.stream.publish(s)
+	}
+}
+
+// Update function when playing as a network client - sends input to the host,
+// predicts the player's own ship and interpolates everything else
+func (g *Game) updateClient() error {
+
+	snapshots, err := g.client.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	// The lobby has a menu item to leave, elsewhere the pause button leaves
+	if g.actionPressed(ControlsSolo, ActionQuit) || (g.mode != ModeLobby && g.pausePressed()) {
+		g.leaveGame()
+	}
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+	latest := snapshots[len(snapshots)-1]
+	g.view.lobby = latest.Lobby
+
+	// Ready up while the host is in the lobby
+	if latest.Mode == ModeLobby {
+		g.lobby.joined.update(g)
+	}
+
+	// Send this tick's controls to the host
+	if latest.Mode == ModePlay && !g.client.spectator {
+		g.view.seq++
+		in := g.controlState(ControlsSolo)
+		if err := g.client.SendInput(g.view.seq, in); err != nil {
+			return errHostDisconnected
+		}
+		g.view.pending = append(g.view.pending, pendingInput{seq: g.view.seq, in: in})
+	}
+
+	// Forget the inputs the host has already applied
+	pending := g.view.pending[:0]
+	for _, p := range g.view.pending {
+		if p.seq > latest.Ack {
+			pending = append(pending, p)
+		}
+	}
+	g.view.pending = pending
+
+	g.mode = latest.Mode
+	g.gameType = latest.GameType
+	g.asteroidStyle = latest.AsteroidStyle
+	g.level = latest.Level
+	g.ticks = latest.Ticks
+	g.lastScore = latest.LastScore
+	g.round = latest.Round
+	g.roundTicks = latest.RoundTicks
+	g.roundWinner = latest.RoundWinner
+
+	AsteroidsInGame = latest.Radar.Asteroids
+	miniAsteroidsInGame = latest.Radar.MiniAsteroids
+	generationGoroutines = latest.Radar.GenerationGoroutines
+	updateGoroutines = latest.Radar.UpdateGoroutines
+	g.view.goroutines = latest.Radar.Goroutines
+
+	from, to, t := interpolationPair(snapshots, time.Now().Add(-netInterpolationDelay))
+	g.applyClientView(&latest.Snapshot, from, to, t)
+	return nil
+}
+
+// Finds the two snapshots either side of the render time and how far between them it is
+func interpolationPair(snapshots []timedSnapshot, renderTime time.Time) (*Snapshot, *Snapshot, float64) {
+
+	for i := len(snapshots) - 1; i > 0; i-- {
+		from, to := &snapshots[i-1], &snapshots[i]
+		if from.received.After(renderTime) {
+			continue
+		}
+		span := to.received.Sub(from.received)
+		if span <= 0 {
+			return &to.Snapshot, &to.Snapshot, 1
+		}
+		t := float64(renderTime.Sub(from.received)) / float64(span)
+		return &from.Snapshot, &to.Snapshot, math.Min(t, 1)
+	}
+
+	// Not enough history yet, draw the oldest snapshot
+	return &snapshots[0].Snapshot, &snapshots[0].Snapshot, 0
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// Interpolates an asteroid's rotation the short way round
+func lerpAngle(a, b, t float64) float64 {
+	if math.Abs(b-a) > maxAngle/2 {
+		return b
+	}
+	return lerp(a, b, t)
+}
+
+// Rebuilds the players and asteroids to draw from the snapshots
+func (g *Game) applyClientView(latest, from, to *Snapshot, t float64) {
+
+	g.players = g.players[:0]
+	for _, ps := range latest.Players {
+		p := &Player{
+			id:           ps.ID,
+			shipXPos:     ps.X,
+			shipYPos:     ps.Y,
+			rocketXPos:   ps.RocketX,
+			rocketYPos:   ps.RocketY,
+			shooting:     ps.Shooting,
+			health:       ps.Health,
+			score:        ps.Score,
+			invulnerable: ps.Invulnerable,
+			respawnTicks: ps.RespawnTicks,
+			roundWins:    ps.RoundWins,
+			eliminations: ps.Eliminations,
+		}
+
+		if ps.ID == g.client.PlayerID() {
+			// Own ship - replay the inputs the host has not applied yet on top of its position
+			for _, pending := range g.view.pending {
+				p.applyInput(pending.in)
+				p.keepInBounds()
+			}
+			if !ps.Shooting {
+				p.resetRocket()
+			}
+		} else if a, b := findPlayerState(from, ps.ID), findPlayerState(to, ps.ID); a != nil && b != nil {
+			p.shipXPos = lerp(a.X, b.X, t)
+			p.shipYPos = lerp(a.Y, b.Y, t)
+			p.rocketXPos = lerp(a.RocketX, b.RocketX, t)
+			p.rocketYPos = lerp(a.RocketY, b.RocketY, t)
+		}
+		g.players = append(g.players, p)
+	}
+
+	g.asteroids.asteroidsList = interpolateAsteroids(g.asteroids.asteroidsList[:0], latest.Asteroids, from.Asteroids, to.Asteroids, t, asteroidWidth, asteroidHeight)
+	g.miniAsteroids.asteroidsList = interpolateAsteroids(g.miniAsteroids.asteroidsList[:0], latest.MiniAsteroids, from.MiniAsteroids, to.MiniAsteroids, t, miniAsteroidWidth, miniAsteroidHeight)
+	AsteroidsInGame = len(g.asteroids.asteroidsList)
+	miniAsteroidsInGame = len(g.miniAsteroids.asteroidsList)
+}
+
+func findPlayerState(s *Snapshot, id int) *PlayerState {
+	for i := range s.Players {
+		if s.Players[i].ID == id {
+			return &s.Players[i]
+		}
+	}
+	return nil
+}
+
+// Builds the asteroids in the latest snapshot, interpolating those in both snapshots by id
+func interpolateAsteroids(list []*Asteroid, latest, from, to []AsteroidState, t float64, w, h int) []*Asteroid {
+
+	fromByID := make(map[uint32]AsteroidState, len(from))
+	for _, a := range from {
+		fromByID[a.ID] = a
+	}
+	toByID := make(map[uint32]AsteroidState, len(to))
+	for _, a := range to {
+		toByID[a.ID] = a
+	}
+
+	for _, s := range latest {
+		a := &Asteroid{id: s.ID, width: w, height: h, x: s.X, y: s.Y, vx: s.VX, vy: s.VY, angle: s.Angle}
+		if f, ok := fromByID[s.ID]; ok {
+			if b, ok := toByID[s.ID]; ok {
+				a.x = lerp(f.X, b.X, t)
+				a.y = lerp(f.Y, b.Y, t)
+				a.angle = lerpAngle(f.Angle, b.Angle, t)
+			}
+		}
+		list = append(list, a)
+	}
+	return list
+}
+
+// Leaves a network game, closing the game as it always has
+func (g *Game) leaveGame() {
+	fmt.Println(tr("console.thanks"))
+	g.client.Close()
+	os.Exit(1)
+}
+
+// Draws the connection status over the menus when hosting or joined
+func (g *Game) drawNetStatus(screen *ebiten.Image) {
+
+	if g.client != nil && g.client.spectator && g.mode == ModePlay {
+		g.print(screen, tr("radar.running", g.view.goroutines), 30, 130)
+		g.print(screen, tr("net.spectating_title"), screenWidth/2-35, 10)
+		return
+	}
+	if g.mode == ModePlay || g.mode == ModeLobby {
+		return
+	}
+
+	var status string
+	if g.client != nil {
+		leave := g.prompt(g.config.Keys[ControlsSolo].label(ActionQuit), padLabel(ActionQuit), "X")
+		if g.device == DevicePointer {
+			g.drawPauseButton(screen)
+		}
+		if g.client.spectator {
+			status = tr("net.spectating", leave)
+		} else {
+			status = tr("net.connected", g.client.PlayerID(), leave)
+		}
+	} else if g.host != nil {
+		status = tr("net.hosting", g.host.Addr(), len(g.host.clientIDs()))
+	} else {
+		return
+	}
+	g.print(screen, status, 10, 10)
+}
@@ -0,0 +1,528 @@
+package main
+
+// Network Multiplayer Protocol
+//
+// A host runs the only real simulation of the game. Clients send the controls
+// their player is holding and draw the state the host sends back.
+//
+// Version 6 of the protocol runs over a single TCP connection per client.
+// Every message is a JSON encoded NetMessage on its own line, with its Type
+// saying which of the other fields are set:
+//
+//	client -> host   "hello"     Version, Name           first message after connecting
+//	host -> client   "welcome"   Version, PlayerID       client has joined the game
+//	host -> client   "reject"    Version, Reason         client can not join, connection is closed
+//	client -> host   "ready"     Ready                   client is (or is no longer) ready to start
+//	client -> host   "input"     Seq, Input              controls held for one client tick
+//	host -> client   "snapshot"  Snapshot                state of the game after one host tick
+//
+// While the host is in the lobby each snapshot also carries the Lobby: who
+// has joined, who is ready and the chosen level. The match starts once every
+// player is ready.
+//
+// Games on the local network are found over UDP (see lobby.go). A client
+// sends a "discover" NetMessage with its Version as a single datagram to the
+// discovery ports, both broadcast and on the loopback interface. Each host of
+// the same version answers with a "session" NetMessage holding its Session,
+// including the TCP port to join on.
+//
+// The client must send "hello" within helloTimeout, and the host rejects any
+// client speaking a different protocol version. A reject's Reason is a code,
+// "version" or "full", which the client explains in the player's language.
+// Inputs are numbered from 1 and the host queues them, applying each as one
+// tick of movement. Inputs sent while the player has no ship are thrown
+// away, as are the oldest once netInputBuffer are waiting. Each snapshot
+// carries Ack, the number of the last input the host applied or threw away
+// for that client, so the client can replay the inputs the host has not seen
+// yet on top of the snapshot (client side prediction). Snapshots are sent
+// every host tick; a client too slow to read them has snapshots dropped
+// rather than holding up the host.
+//
+// The same snapshots are streamed to spectators (see spectator.go).
+//
+// Any change to the messages or their meaning must bump protocolVersion.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	protocolVersion = 6
+
+	// Port a host listens on when none is given
+	defaultNetPort = "7777"
+
+	// Players in a networked game, including the host
+	maxNetPlayers = 4
+
+	// Time a new connection has to say hello
+	helloTimeout = 5 * time.Second
+
+	// Messages waiting to be written to a client before snapshots are dropped
+	netSendBuffer = 8
+
+	// Inputs queued for a client before the oldest are dropped, half a second of ticks
+	netInputBuffer = 30
+
+	// Snapshots a client keeps for interpolation
+	netSnapshotHistory = 32
+)
+
+// Message types
+const (
+	msgHello    = "hello"
+	msgWelcome  = "welcome"
+	msgReject   = "reject"
+	msgReady    = "ready"
+	msgInput    = "input"
+	msgSnapshot = "snapshot"
+	msgDiscover = "discover"
+	msgSession  = "session"
+)
+
+// Reasons a host gives for rejecting a join
+const (
+	rejectVersion = "version"
+	rejectFull    = "full"
+)
+
+var errHostDisconnected = errors.New("disconnected from host")
+
+// Hosts started by this game, added to the session id so two started on the same clock tick differ
+var hostsStarted int64
+
+// Message sent between host and clients
+type NetMessage struct {
+	Type     string       `json:"type"`
+	Version  int          `json:"version,omitempty"`
+	Name     string       `json:"name,omitempty"`
+	PlayerID int          `json:"playerId,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+	Ready    bool         `json:"ready,omitempty"`
+	Seq      uint32       `json:"seq,omitempty"`
+	Input    *InputState  `json:"input,omitempty"`
+	Snapshot *Snapshot    `json:"snapshot,omitempty"`
+	Session  *SessionInfo `json:"session,omitempty"`
+}
+
+// State of the game after one host tick
+type Snapshot struct {
+	Tick int    `json:"tick"`
+	Ack  uint32 `json:"ack"`
+
+	Mode          Mode          `json:"mode"`
+	GameType      GameType      `json:"gameType"`
+	AsteroidStyle AsteroidStyle `json:"asteroidStyle"`
+	Level         int           `json:"level"`
+	Ticks         int           `json:"ticks"`
+	LastScore     int           `json:"lastScore"`
+	Round         int           `json:"round,omitempty"`
+	RoundTicks    int           `json:"roundTicks,omitempty"`
+	RoundWinner   int           `json:"roundWinner,omitempty"`
+
+	Players       []PlayerState   `json:"players"`
+	Asteroids     []AsteroidState `json:"asteroids"`
+	MiniAsteroids []AsteroidState `json:"miniAsteroids"`
+	Radar         RadarState      `json:"radar"`
+	Lobby         *LobbyState     `json:"lobby,omitempty"`
+}
+
+// State of one player's ship and rocket
+type PlayerState struct {
+	ID           int     `json:"id"`
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+	RocketX      float64 `json:"rocketX"`
+	RocketY      float64 `json:"rocketY"`
+	Shooting     bool    `json:"shooting,omitempty"`
+	Health       int     `json:"health"`
+	Score        int     `json:"score"`
+	Invulnerable int     `json:"invulnerable,omitempty"`
+	RespawnTicks int     `json:"respawnTicks,omitempty"`
+	RoundWins    int     `json:"roundWins,omitempty"`
+	Eliminations int     `json:"eliminations,omitempty"`
+}
+
+// State of one asteroid, with its direction so clients can tell which are heading for a ship
+type AsteroidState struct {
+	ID    uint32  `json:"id"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	VX    float64 `json:"vx"`
+	VY    float64 `json:"vy"`
+	Angle float64 `json:"angle"`
+}
+
+// Counters shown on the concurrency radar, with the goroutines running in the host right now
+type RadarState struct {
+	Asteroids            int    `json:"asteroids"`
+	MiniAsteroids        int    `json:"miniAsteroids"`
+	GenerationGoroutines uint32 `json:"generationGoroutines"`
+	UpdateGoroutines     uint32 `json:"updateGoroutines"`
+	Goroutines           int    `json:"goroutines"`
+}
+
+// Host side of a networked game - accepts clients and queues their inputs
+type NetHost struct {
+	listener  net.Listener
+	discovery *net.UDPConn
+	name      string
+	sessionID int64
+
+	mu      sync.Mutex
+	clients map[int]*netConn
+	joined  []int
+	left    []int
+	session SessionInfo
+}
+
+// Host side of one client connection
+type netConn struct {
+	id    int
+	name  string
+	conn  net.Conn
+	out   chan NetMessage
+	ready bool
+
+	// Inputs waiting to be applied, and the number of the last one taken to be applied
+	mu      sync.Mutex
+	inputs  []queuedInput
+	applied uint32
+}
+
+// Input received from a client with its number
+type queuedInput struct {
+	seq uint32
+	in  InputState
+}
+
+// Starts hosting a game on the given address, e.g. ":7777"
+func NewNetHost(addr, name string) (*NetHost, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	h := &NetHost{
+		listener:  l,
+		name:      name,
+		sessionID: time.Now().UnixNano() + atomic.AddInt64(&hostsStarted, 1),
+		clients:   make(map[int]*netConn),
+	}
+	go h.acceptLoop()
+	return h, nil
+}
+
+// Returns the address the host is listening on
+func (h *NetHost) Addr() net.Addr {
+	return h.listener.Addr()
+}
+
+// Stops hosting and disconnects every client
+func (h *NetHost) Close() error {
+	err := h.listener.Close()
+	if h.discovery != nil {
+		h.discovery.Close()
+	}
+	h.mu.Lock()
+	for _, c := range h.clients {
+		c.conn.Close()
+	}
+	h.mu.Unlock()
+	return err
+}
+
+func (h *NetHost) acceptLoop() {
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.handshake(conn)
+	}
+}
+
+// Reads the client's hello and gives it a player id, or rejects it
+func (h *NetHost) handshake(conn net.Conn) {
+
+	conn.SetDeadline(time.Now().Add(helloTimeout))
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	enc := json.NewEncoder(conn)
+
+	var hello NetMessage
+	if err := dec.Decode(&hello); err != nil || hello.Type != msgHello {
+		conn.Close()
+		return
+	}
+	if hello.Version != protocolVersion {
+		enc.Encode(NetMessage{Type: msgReject, Version: protocolVersion, Reason: rejectVersion})
+		conn.Close()
+		return
+	}
+
+	c := &netConn{name: hello.Name, conn: conn, out: make(chan NetMessage, netSendBuffer)}
+
+	h.mu.Lock()
+	// The host is player 1, clients take the first free id after it
+	for id := 2; id <= maxNetPlayers; id++ {
+		if h.clients[id] == nil {
+			c.id = id
+			break
+		}
+	}
+	if c.id == 0 {
+		h.mu.Unlock()
+		enc.Encode(NetMessage{Type: msgReject, Version: protocolVersion, Reason: rejectFull})
+		conn.Close()
+		return
+	}
+	h.clients[c.id] = c
+	h.mu.Unlock()
+
+	if err := enc.Encode(NetMessage{Type: msgWelcome, Version: protocolVersion, PlayerID: c.id}); err != nil {
+		h.drop(c)
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	fmt.Printf("%s \n", tr("console.player_joined", c.id, c.name, conn.RemoteAddr()))
+
+	h.mu.Lock()
+	h.joined = append(h.joined, c.id)
+	h.mu.Unlock()
+
+	go h.writeLoop(c, enc)
+	h.readLoop(c, dec)
+}
+
+// Queues every input sent by the client
+func (h *NetHost) readLoop(c *netConn, dec *json.Decoder) {
+	for {
+		var msg NetMessage
+		if err := dec.Decode(&msg); err != nil {
+			h.drop(c)
+			return
+		}
+		switch {
+		case msg.Type == msgInput && msg.Input != nil:
+			c.mu.Lock()
+			c.inputs = append(c.inputs, queuedInput{seq: msg.Seq, in: *msg.Input})
+			if len(c.inputs) > netInputBuffer {
+				c.inputs = c.inputs[len(c.inputs)-netInputBuffer:]
+			}
+			c.mu.Unlock()
+		case msg.Type == msgReady:
+			h.mu.Lock()
+			c.ready = msg.Ready
+			h.mu.Unlock()
+		}
+	}
+}
+
+// Writes queued messages to the client
+func (h *NetHost) writeLoop(c *netConn, enc *json.Encoder) {
+	for msg := range c.out {
+		if err := enc.Encode(msg); err != nil {
+			h.drop(c)
+			return
+		}
+	}
+}
+
+// Removes a client that has disconnected
+func (h *NetHost) drop(c *netConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[c.id] != c {
+		return
+	}
+	delete(h.clients, c.id)
+	close(c.out)
+	c.conn.Close()
+	h.left = append(h.left, c.id)
+	fmt.Printf("%s \n", tr("console.player_left", c.id, c.name))
+}
+
+// Returns the ids of players that joined and left since the last call
+func (h *NetHost) changes() (joined, left []int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	joined, left = h.joined, h.left
+	h.joined, h.left = nil, nil
+	return joined, left
+}
+
+// Returns the ids of the connected clients
+func (h *NetHost) clientIDs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var ids []int
+	for id := 2; id <= maxNetPlayers; id++ {
+		if h.clients[id] != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Returns the inputs a client has sent since the last call, which are acknowledged in the next snapshot
+func (h *NetHost) takeInputs(id int) []InputState {
+	h.mu.Lock()
+	c := h.clients[id]
+	h.mu.Unlock()
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var inputs []InputState
+	for _, q := range c.inputs {
+		inputs = append(inputs, q.in)
+		c.applied = q.seq
+	}
+	c.inputs = nil
+	return inputs
+}
+
+// Sends a snapshot to every client, each with its own input acknowledgement
+func (h *NetHost) broadcast(s Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range h.clients {
+		c.mu.Lock()
+		s.Ack = c.applied
+		c.mu.Unlock()
+
+		snapshot := s
+		select {
+		case c.out <- NetMessage{Type: msgSnapshot, Snapshot: &snapshot}:
+		default:
+			// Client is not keeping up, skip this snapshot
+		}
+	}
+}
+
+// Client side of a networked game, or a spectator watching a stream
+type NetClient struct {
+	conn      net.Conn
+	enc       *json.Encoder
+	playerID  int
+	spectator bool
+
+	mu        sync.Mutex
+	snapshots []timedSnapshot
+	err       error
+}
+
+// Snapshot with the time the client received it
+type timedSnapshot struct {
+	Snapshot
+	received time.Time
+}
+
+// Connects to a host and joins its game
+func DialNetClient(addr, name string) (*NetClient, error) {
+	conn, err := net.DialTimeout("tcp", addr, helloTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &NetClient{conn: conn, enc: json.NewEncoder(conn)}
+	dec := json.NewDecoder(bufio.NewReader(conn))
+
+	conn.SetDeadline(time.Now().Add(helloTimeout))
+	if err := c.enc.Encode(NetMessage{Type: msgHello, Version: protocolVersion, Name: name}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var reply NetMessage
+	if err := dec.Decode(&reply); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if reply.Type != msgWelcome {
+		conn.Close()
+		return nil, &RejectError{Reason: reply.Reason, Version: reply.Version}
+	}
+	conn.SetDeadline(time.Time{})
+
+	c.playerID = reply.PlayerID
+	go c.readLoop(dec)
+	return c, nil
+}
+
+// A host would not let the client join, for one of the reject reasons
+type RejectError struct {
+	Reason  string
+	Version int
+}
+
+// Explains the reason in the player's language
+func (e *RejectError) Error() string {
+	switch e.Reason {
+	case rejectVersion:
+		return tr("net.reject_version", e.Version, protocolVersion)
+	case rejectFull:
+		return tr("net.reject_full")
+	}
+	return tr("net.reject_unknown")
+}
+
+// Keeps the latest snapshots sent by the host
+func (c *NetClient) readLoop(dec *json.Decoder) {
+	for {
+		var msg NetMessage
+		if err := dec.Decode(&msg); err != nil {
+			c.mu.Lock()
+			c.err = errHostDisconnected
+			c.mu.Unlock()
+			return
+		}
+		if msg.Type != msgSnapshot || msg.Snapshot == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		c.snapshots = append(c.snapshots, timedSnapshot{Snapshot: *msg.Snapshot, received: time.Now()})
+		if len(c.snapshots) > netSnapshotHistory {
+			c.snapshots = c.snapshots[len(c.snapshots)-netSnapshotHistory:]
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Tells the host whether the player is ready to start
+func (c *NetClient) SendReady(ready bool) error {
+	return c.enc.Encode(NetMessage{Type: msgReady, Ready: ready})
+}
+
+// Sends the controls held for one tick to the host
+func (c *NetClient) SendInput(seq uint32, in InputState) error {
+	return c.enc.Encode(NetMessage{Type: msgInput, Seq: seq, Input: &in})
+}
+
+// Returns the snapshots received so far, oldest first, or an error once the host has gone
+func (c *NetClient) Snapshots() ([]timedSnapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshots := make([]timedSnapshot, len(c.snapshots))
+	copy(snapshots, c.snapshots)
+	return snapshots, c.err
+}
+
+// Returns the player id the host gave this client
+func (c *NetClient) PlayerID() int {
+	return c.playerID
+}
+
+// Leaves the game
+func (c *NetClient) Close() error {
+	return c.conn.Close()
+}
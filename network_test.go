@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// Starts a host on a free loopback port, closed when the test ends
+func startTestHost(t *testing.T, name string) *NetHost {
+	t.Helper()
+	h, err := NewNetHost("127.0.0.1:0", name)
+	if err != nil {
+		t.Fatalf("starting host: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+// Joins a host, left when the test ends
+func dialTestClient(t *testing.T, h *NetHost, name string) *NetClient {
+	t.Helper()
+	c, err := DialNetClient(h.Addr().String(), name)
+	if err != nil {
+		t.Fatalf("joining host: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// Waits for a condition the host reaches on its own Go routines
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandshakeWelcomesClients(t *testing.T) {
+	h := startTestHost(t, "host")
+
+	first := dialTestClient(t, h, "first")
+	second := dialTestClient(t, h, "second")
+	if first.PlayerID() != 2 || second.PlayerID() != 3 {
+		t.Fatalf("player ids = %d, %d, want 2, 3", first.PlayerID(), second.PlayerID())
+	}
+
+	var joined []int
+	waitFor(t, "both clients to join", func() bool {
+		j, left := h.changes()
+		if len(left) != 0 {
+			t.Fatalf("players %v left", left)
+		}
+		joined = append(joined, j...)
+		return len(joined) == 2
+	})
+}
+
+func TestHandshakeRejectsOtherVersions(t *testing.T) {
+	h := startTestHost(t, "host")
+
+	conn, err := net.Dial("tcp", h.Addr().String())
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(NetMessage{Type: msgHello, Version: protocolVersion - 1, Name: "old"}); err != nil {
+		t.Fatalf("sending hello: %v", err)
+	}
+
+	var reply NetMessage
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&reply); err != nil {
+		t.Fatalf("reading reply: %v", err)
+	}
+	if reply.Type != msgReject || reply.Version != protocolVersion || reply.Reason != rejectVersion {
+		t.Fatalf("reply = %+v, want a version reject with version %d", reply, protocolVersion)
+	}
+	if len(h.clientIDs()) != 0 {
+		t.Fatalf("rejected client was added")
+	}
+}
+
+func TestHandshakeRejectsWhenFull(t *testing.T) {
+	h := startTestHost(t, "host")
+	for i := 2; i <= maxNetPlayers; i++ {
+		dialTestClient(t, h, "player")
+	}
+
+	_, err := DialNetClient(h.Addr().String(), "late")
+	var reject *RejectError
+	if !errors.As(err, &reject) || reject.Reason != rejectFull {
+		t.Fatalf("joining a full game: err = %v, want a full reject", err)
+	}
+	if err.Error() != tr("net.reject_full") {
+		t.Fatalf("reject explained as %q, want %q", err.Error(), tr("net.reject_full"))
+	}
+}
+
+func TestInputsAreAcknowledgedOnceTaken(t *testing.T) {
+	h := startTestHost(t, "host")
+	c := dialTestClient(t, h, "client")
+
+	// Count the queued inputs without taking them
+	queued := func() int {
+		h.mu.Lock()
+		conn := h.clients[c.PlayerID()]
+		h.mu.Unlock()
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		return len(conn.inputs)
+	}
+	// Sends a snapshot and returns its acknowledgement once it arrives
+	ack := func(tick int) uint32 {
+		h.broadcast(Snapshot{Tick: tick})
+		var got uint32
+		waitFor(t, "a snapshot", func() bool {
+			snapshots, _ := c.Snapshots()
+			if len(snapshots) == 0 || snapshots[len(snapshots)-1].Tick != tick {
+				return false
+			}
+			got = snapshots[len(snapshots)-1].Ack
+			return true
+		})
+		return got
+	}
+
+	waitFor(t, "the client to join", func() bool { return len(h.clientIDs()) == 1 })
+	for seq := uint32(1); seq <= 3; seq++ {
+		if err := c.SendInput(seq, InputState{Left: seq == 2}); err != nil {
+			t.Fatalf("sending input: %v", err)
+		}
+	}
+	waitFor(t, "the inputs to queue", func() bool { return queued() == 3 })
+
+	// Received but not yet applied
+	if got := ack(1); got != 0 {
+		t.Fatalf("ack before taking inputs = %d, want 0", got)
+	}
+
+	inputs := h.takeInputs(c.PlayerID())
+	if len(inputs) != 3 || inputs[0].Left || !inputs[1].Left || inputs[2].Left {
+		t.Fatalf("inputs = %+v, want three in order with the second moving left", inputs)
+	}
+	if got := ack(2); got != 3 {
+		t.Fatalf("ack after taking inputs = %d, want 3", got)
+	}
+	if inputs := h.takeInputs(c.PlayerID()); len(inputs) != 0 {
+		t.Fatalf("inputs taken twice: %+v", inputs)
+	}
+}
+
+func TestInputQueueIsCapped(t *testing.T) {
+	h := startTestHost(t, "host")
+	c := dialTestClient(t, h, "client")
+
+	count := uint32(netInputBuffer + 10)
+	for seq := uint32(1); seq <= count; seq++ {
+		if err := c.SendInput(seq, InputState{}); err != nil {
+			t.Fatalf("sending input: %v", err)
+		}
+	}
+	waitFor(t, "the last input", func() bool {
+		h.mu.Lock()
+		conn := h.clients[c.PlayerID()]
+		h.mu.Unlock()
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		return len(conn.inputs) > 0 && conn.inputs[len(conn.inputs)-1].seq == count
+	})
+
+	if inputs := h.takeInputs(c.PlayerID()); len(inputs) != netInputBuffer {
+		t.Fatalf("queued inputs = %d, want %d", len(inputs), netInputBuffer)
+	}
+}
+
+func TestInterpolationPair(t *testing.T) {
+	start := time.Now()
+	snapshots := []timedSnapshot{
+		{Snapshot: Snapshot{Tick: 1}, received: start},
+		{Snapshot: Snapshot{Tick: 2}, received: start.Add(100 * time.Millisecond)},
+		{Snapshot: Snapshot{Tick: 3}, received: start.Add(200 * time.Millisecond)},
+	}
+
+	tests := []struct {
+		render   time.Duration
+		from, to int
+		t        float64
+	}{
+		{-50 * time.Millisecond, 1, 1, 0},
+		{25 * time.Millisecond, 1, 2, 0.25},
+		{150 * time.Millisecond, 2, 3, 0.5},
+		{300 * time.Millisecond, 2, 3, 1},
+	}
+	for _, test := range tests {
+		from, to, got := interpolationPair(snapshots, start.Add(test.render))
+		if from.Tick != test.from || to.Tick != test.to || got != test.t {
+			t.Errorf("render at %v: ticks %d-%d at %v, want %d-%d at %v",
+				test.render, from.Tick, to.Tick, got, test.from, test.to, test.t)
+		}
+	}
+}
+
+func TestInterpolateAsteroids(t *testing.T) {
+	from := []AsteroidState{{ID: 1, X: 0, Y: 0, Angle: 10}, {ID: 2, X: 50, Y: 50}}
+	to := []AsteroidState{{ID: 1, X: 100, Y: 40, Angle: 20}, {ID: 3, X: 7, Y: 8}}
+	latest := []AsteroidState{{ID: 1, X: 200, Y: 80, VX: -1, VY: 1, Angle: 30}, {ID: 3, X: 9, Y: 9}}
+
+	list := interpolateAsteroids(nil, latest, from, to, 0.5, asteroidWidth, asteroidHeight)
+	if len(list) != 2 {
+		t.Fatalf("asteroids = %d, want the 2 in the latest snapshot", len(list))
+	}
+
+	// In both snapshots, drawn half way between them
+	if a := list[0]; a.id != 1 || a.x != 50 || a.y != 20 || a.angle != 15 || a.width != asteroidWidth {
+		t.Errorf("asteroid 1 = %+v, want half way at 50,20 angle 15", *a)
+	}
+	// Moving the way it is now, so threat arrows can be drawn for it
+	if a := list[0]; a.vx != -1 || a.vy != 1 {
+		t.Errorf("asteroid 1 velocity = %v,%v, want -1,1", a.vx, a.vy)
+	}
+	// Only in the latest snapshot, drawn where it is now
+	if a := list[1]; a.id != 3 || a.x != 9 || a.y != 9 {
+		t.Errorf("asteroid 3 = %+v, want its latest position 9,9", *a)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Most particles alive at once - new ones are dropped while the pool is full
+	maxParticles = 1500
+)
+
+// Particle is a single short lived dot of colour
+type Particle struct {
+	x, y   float64
+	vx, vy float64
+	life   int
+	ttl    int
+	size   float64
+	drag   float64
+	colour color.RGBA
+}
+
+// Emitter describes a burst of particles
+type Emitter struct {
+	count int
+
+	// Direction the particles travel in, in radians, spread either side of it
+	direction float64
+	spread    float64
+
+	speedMin, speedMax float64
+	lifeMin, lifeMax   int
+	size               float64
+
+	// Picked from at random for each particle
+	colours []color.RGBA
+
+	// Slows the particles each tick, 1 keeps their speed and 0 stops them
+	drag float64
+}
+
+var (
+	explosionEmitter = Emitter{
+		count: 40, spread: math.Pi,
+		speedMin: 0.5, speedMax: 4, lifeMin: 20, lifeMax: 50, size: 3, drag: 0.96,
+		colours: []color.RGBA{{0xff, 0xd0, 0x40, 0xff}, {0xff, 0x80, 0x20, 0xff}, {0xa0, 0xa0, 0xa0, 0xff}},
+	}
+	miniExplosionEmitter = Emitter{
+		count: 15, spread: math.Pi,
+		speedMin: 0.5, speedMax: 3, lifeMin: 15, lifeMax: 35, size: 2, drag: 0.95,
+		colours: []color.RGBA{{0xff, 0xd0, 0x40, 0xff}, {0xa0, 0xa0, 0xa0, 0xff}},
+	}
+	thrustEmitter = Emitter{
+		count: 3, direction: math.Pi / 2, spread: 0.3,
+		speedMin: 2, speedMax: 4, lifeMin: 8, lifeMax: 16, size: 2, drag: 0.9,
+		colours: []color.RGBA{{0xff, 0xa0, 0x30, 0xff}, {0xff, 0xff, 0x80, 0xff}},
+	}
+	impactEmitter = Emitter{
+		count: 8, direction: math.Pi / 2, spread: 1.2,
+		speedMin: 1, speedMax: 3, lifeMin: 6, lifeMax: 14, size: 2, drag: 0.9,
+		colours: []color.RGBA{{0xff, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xa0, 0xff}},
+	}
+	shieldEmitter = Emitter{
+		count: 4, spread: math.Pi,
+		speedMin: 1, speedMax: 2, lifeMin: 10, lifeMax: 20, size: 2, drag: 0.95,
+		colours: []color.RGBA{{0x40, 0xa0, 0xff, 0xff}, {0xa0, 0xe0, 0xff, 0xff}},
+	}
+)
+
+// ParticleSystem keeps its particles in a fixed pool, so none are allocated during play.
+// Live particles are kept at the front of the pool.
+type ParticleSystem struct {
+	pool []Particle
+	live int
+
+	// Draws the particles fainter, for players sensitive to flashing
+	dim bool
+}
+
+// 1x1 white image, scaled and tinted to draw particles and other shapes
+var whiteImage *ebiten.Image
+
+func whitePixel() *ebiten.Image {
+	if whiteImage == nil {
+		whiteImage = ebiten.NewImage(1, 1)
+		whiteImage.Fill(color.White)
+	}
+	return whiteImage
+}
+
+// Starts a burst of particles at the given point
+func (s *ParticleSystem) emit(e Emitter, x, y float64) {
+
+	if s.pool == nil {
+		s.pool = make([]Particle, maxParticles)
+	}
+
+	for i := 0; i < e.count && s.live < len(s.pool); i++ {
+		angle := e.direction + (rand.Float64()*2-1)*e.spread
+		speed := e.speedMin + rand.Float64()*(e.speedMax-e.speedMin)
+		ttl := e.lifeMin + rand.Intn(e.lifeMax-e.lifeMin+1)
+
+		s.pool[s.live] = Particle{
+			x: x, y: y,
+			vx:     math.Cos(angle) * speed,
+			vy:     math.Sin(angle) * speed,
+			ttl:    ttl,
+			size:   e.size,
+			drag:   e.drag,
+			colour: e.colours[rand.Intn(len(e.colours))],
+		}
+		s.live++
+	}
+}
+
+// Moves every particle on and returns the expired ones to the pool
+func (s *ParticleSystem) update() {
+	for i := 0; i < s.live; {
+		p := &s.pool[i]
+		p.life++
+		if p.life >= p.ttl {
+			// Swap the last live particle into this slot
+			s.live--
+			s.pool[i] = s.pool[s.live]
+			continue
+		}
+		p.x += p.vx
+		p.y += p.vy
+		p.vx *= p.drag
+		p.vy *= p.drag
+		i++
+	}
+}
+
+// Removes every particle
+func (s *ParticleSystem) clear() {
+	s.live = 0
+}
+
+// Draws the particles through the camera transform, fading each out over its lifetime
+func (s *ParticleSystem) draw(screen *ebiten.Image, camera ebiten.GeoM) {
+
+	pixel := whitePixel()
+	op := &ebiten.DrawImageOptions{}
+	for i := 0; i < s.live; i++ {
+		p := &s.pool[i]
+		fade := 1 - float64(p.life)/float64(p.ttl)
+		if s.dim {
+			fade *= 0.35
+		}
+
+		op.GeoM.Reset()
+		op.GeoM.Scale(p.size, p.size)
+		op.GeoM.Translate(p.x-p.size/2, p.y-p.size/2)
+		op.GeoM.Concat(camera)
+		op.ColorM.Reset()
+		op.ColorM.Scale(float64(p.colour.R)/0xff, float64(p.colour.G)/0xff, float64(p.colour.B)/0xff, fade)
+		screen.DrawImage(pixel, op)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	playerMaxHealth = 100
+
+	// Health lost when hit by the other player's rocket
+	friendlyFireDamage = 10
+)
+
+// Player Object Type - each player flies their own ship and rocket
+type Player struct {
+	id int
+
+	shipXPos, shipYPos     float64
+	rocketXPos, rocketYPos float64
+
+	shooting bool
+	health   int
+	score    int
+	controls ControlSet
+
+	// Remote players are flown by a network client instead of the keyboard
+	remote bool
+
+	// Versus match state
+	spawnXPos       float64
+	respawnTicks    int
+	invulnerable    int
+	roundWins       int
+	roundStartScore int
+	eliminations    int
+}
+
+// Controls held down by a player for one tick
+type InputState struct {
+	Left  bool `json:"left,omitempty"`
+	Right bool `json:"right,omitempty"`
+	Up    bool `json:"up,omitempty"`
+	Down  bool `json:"down,omitempty"`
+	Fire  bool `json:"fire,omitempty"`
+
+	// Position of a gamepad's stick from -1 to 1, moving the ship as well as the keys
+	StickX float64 `json:"stickX,omitempty"`
+	StickY float64 `json:"stickY,omitempty"`
+}
+
+// Colour tints to tell the ships apart, by player id, from the palette in use (see useColours)
+var playerTints = palettes[PaletteStandard].tints
+
+// Creates a player with their ship at the bottom of the world
+func newPlayer(id int, controls ControlSet) *Player {
+	p := &Player{
+		id:       id,
+		health:   playerMaxHealth,
+		controls: controls,
+	}
+	p.shipYPos = float64(worldHeight) - float64(shipHeight*2)
+	p.resetRocket()
+	return p
+}
+
+// Creates the players for a new game, spread evenly across the screen
+func (g *Game) initPlayers() {
+
+	// Players connected over the network replace the local second player
+	remotes := g.remotePlayerIDs()
+	local := g.playerCount()
+	if len(remotes) > 0 {
+		local = 1
+	}
+
+	g.players = nil
+	if local == 1 {
+		g.players = append(g.players, newPlayer(1, ControlsSolo))
+	} else {
+		g.players = append(g.players, newPlayer(1, ControlsPlayerOne), newPlayer(2, ControlsPlayerTwo))
+	}
+	for _, id := range remotes {
+		// Versus is always one on one
+		if g.gameType == TypeVersus && len(g.players) == 2 {
+			break
+		}
+		p := newPlayer(id, ControlsSolo)
+		p.remote = true
+		g.players = append(g.players, p)
+	}
+
+	g.spreadPlayers()
+}
+
+// Moves every ship to its starting position, spread evenly across the world
+func (g *Game) spreadPlayers() {
+	for i, p := range g.players {
+		p.shipXPos = float64(worldWidth*(i+1)/(len(g.players)+1)) - float64(shipWidth/2)
+		p.spawnXPos = p.shipXPos
+		p.resetRocket()
+	}
+}
+
+// Returns how many players the next game is set up for
+func (g *Game) playerCount() int {
+	if g.coop || g.gameType == TypeVersus {
+		return 2
+	}
+	return 1
+}
+
+// Checks if the player is still in the game
+func (p *Player) alive() bool {
+	return p.health > 0
+}
+
+// Puts the rocket back under the ship, ready to fire
+func (p *Player) resetRocket() {
+	p.shooting = false
+	p.rocketXPos = p.shipXPos + float64(shipWidth/2) - 1.5
+	p.rocketYPos = p.shipYPos + float64(shipHeight/2)
+}
+
+// Moves the ship (and the rocket with it) for one tick of input
+func (p *Player) applyInput(in InputState) {
+
+	// Keys and the d-pad move at full speed, the stick as fast as it is pushed
+	x, y := in.StickX, in.StickY
+	if in.Right {
+		x++
+	}
+	if in.Left {
+		x--
+	}
+	if in.Down {
+		y++
+	}
+	if in.Up {
+		y--
+	}
+	x, y = clampFloat(x, -1, 1), clampFloat(y, -1, 1)
+
+	// Ships move back faster than they fly forward
+	dx, dy := x*10, y*10
+	if y < 0 {
+		dy = y * 4
+	}
+	p.shipXPos += dx
+	p.rocketXPos += dx
+	p.shipYPos += dy
+	p.rocketYPos += dy
+
+	if in.Fire {
+		p.shooting = true
+	}
+}
+
+// Do not allow ship to fly out of bounds
+func (p *Player) keepInBounds() {
+	// - Don't allow ship to pass side boundaries
+	if p.shipXPos >= float64(worldWidth)-float64(shipWidth) {
+		p.shipXPos = float64(worldWidth) - float64(shipWidth)
+		p.resetRocket()
+	}
+	if p.shipXPos <= 0 {
+		p.shipXPos = 0
+		p.resetRocket()
+	}
+
+	// - Don't allow ship to pass top-down boundaries
+	if p.shipYPos <= 0 {
+		p.shipYPos = 0
+		p.resetRocket()
+	}
+	if p.shipYPos >= float64(worldHeight)-float64(shipHeight) {
+		p.shipYPos = float64(worldHeight) - float64(shipHeight)
+		p.resetRocket()
+	}
+}
+
+// Checks if a player's rocket has hit another player's ship
+func (g *Game) friendlyFireCheck(damage int) {
+
+	w, h := g.rocket.Size()
+
+	for _, shooter := range g.players {
+		if !shooter.alive() || !shooter.shooting {
+			continue
+		}
+		for _, target := range g.players {
+			if target == shooter || !target.alive() || target.invulnerable > 0 {
+				continue
+			}
+			if shooter.rocketXPos < target.shipXPos+shipWidth &&
+				shooter.rocketXPos+float64(w) > target.shipXPos &&
+				shooter.rocketYPos < target.shipYPos+shipHeight &&
+				shooter.rocketYPos+float64(h) > target.shipYPos {
+
+				g.particles.emit(impactEmitter, shooter.rocketXPos, shooter.rocketYPos)
+				g.particles.emit(shieldEmitter, target.shipXPos+shipWidth/2, target.shipYPos+shipHeight/2)
+				if !target.remote {
+					g.shake(shakeRocketHit)
+					g.audio.play(SoundDamage)
+				}
+				shooter.resetRocket()
+				mu.Lock()
+				target.health -= damage
+				mu.Unlock()
+				g.session.Hits++
+				g.session.DamageTaken += damage
+				fmt.Printf("%s \n", tr("console.player_hit", shooter.id, target.id))
+
+				if !target.alive() {
+					g.eliminated(shooter, target)
+				}
+			}
+		}
+	}
+}
+
+// Checks if any player is still in the game
+func (g *Game) playersAlive() bool {
+	for _, p := range g.players {
+		if p.alive() {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the combined score of all players
+func (g *Game) totalScore() int {
+	score := 0
+	for _, p := range g.players {
+		score += p.score
+	}
+	return score
+}
+
+// Draws a health box for each player, player two on the right hand side
+// and players three and four in a row above
+func (g *Game) drawPlayerHealth(screen *ebiten.Image) {
+
+	for i, p := range g.players {
+		x := float64((i % 2) * (screenWidth - 240))
+		y := float64(fromBottom(40) - (i/2)*70)
+
+		drawOptions := &ebiten.DrawImageOptions{}
+		drawOptions.GeoM.Translate(x, y)
+		screen.DrawImage(g.gamePlayerHealth, drawOptions)
+
+		health := p.health
+		if health < 0 {
+			health = 0
+		}
+		g.print(screen, fmt.Sprint(health), int(x)+210, int(y)+12)
+
+		if len(g.players) > 1 {
+			g.print(screen, tr("hud.player_score", p.id, p.score), int(x)+10, int(y)-20)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// How far the virtual stick's knob can move from where the touch started
+	touchStickRadius = 50
+
+	// Size of the touch buttons in play
+	fireButtonRadius  = 45
+	pauseButtonRadius = 22
+
+	// Distance between the mouse and the ship at which dragging moves it at full speed
+	mouseFullSpeed = 60
+)
+
+var (
+	touchButtonColour = color.RGBA{0xff, 0xff, 0xff, 0x30}
+	touchKnobColour   = color.RGBA{0xff, 0xff, 0xff, 0x60}
+)
+
+// Centres of the touch buttons, kept to the corners of the screen - fire above player two's
+// health, and pause with the zoom buttons to its right at the top
+func fireButton() (int, int) {
+	return screenWidth - 85, screenHeight - 140
+}
+
+func pauseButton() (int, int) {
+	return screenWidth / 2, 30
+}
+
+func zoomButton(by int) (int, int) {
+	if by < 0 {
+		return screenWidth/2 + 55, 30
+	}
+	return screenWidth/2 + 105, 30
+}
+
+// Pointer is the mouse and any touches this tick, read once so the menus and ships see the same
+type Pointer struct {
+	// Mouse position, whether it moved and whether the left button went down this tick
+	mouseX, mouseY int
+	mouseMoved     bool
+	clicked        bool
+
+	// Dragging the ship with the mouse, only when the button went down during play
+	dragging bool
+
+	// Touch driving the virtual stick, where it started and where it is now
+	stickTouch               ebiten.TouchID
+	stickActive              bool
+	stickStartX, stickStartY int
+	stickX, stickY           int
+
+	// Fire button held down, and where a touch went down this tick
+	fireHeld   bool
+	tapped     bool
+	tapX, tapY int
+
+	// The last pointer used was a finger, so the touch buttons are drawn
+	touch bool
+}
+
+// Reads the mouse and touches, starting and ending the virtual stick and mouse drags
+func (g *Game) updatePointer() {
+
+	pt := &g.pointer
+	x, y := ebiten.CursorPosition()
+	pt.mouseMoved = x != pt.mouseX || y != pt.mouseY
+	pt.mouseX, pt.mouseY = x, y
+	pt.clicked = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	if pt.clicked {
+		g.device, pt.touch = DevicePointer, false
+		pt.dragging = g.mode == ModePlay && !onButton(x, y)
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		pt.dragging = false
+	}
+
+	pt.tapped = false
+	for _, id := range inpututil.JustPressedTouchIDs() {
+		g.device, pt.touch = DevicePointer, true
+		tx, ty := ebiten.TouchPosition(id)
+		pt.tapped, pt.tapX, pt.tapY = true, tx, ty
+
+		// A touch on the left half of the screen during play becomes the stick, centred where it landed
+		if g.mode == ModePlay && !pt.stickActive && tx < screenWidth/2 {
+			pt.stickTouch, pt.stickActive = id, true
+			pt.stickStartX, pt.stickStartY = tx, ty
+		}
+	}
+	if pt.stickActive {
+		if inpututil.IsTouchJustReleased(pt.stickTouch) {
+			pt.stickActive = false
+		} else {
+			pt.stickX, pt.stickY = ebiten.TouchPosition(pt.stickTouch)
+		}
+	}
+
+	pt.fireHeld = false
+	fx, fy := fireButton()
+	for _, id := range ebiten.TouchIDs() {
+		tx, ty := ebiten.TouchPosition(id)
+		if math.Hypot(float64(tx-fx), float64(ty-fy)) <= fireButtonRadius {
+			pt.fireHeld = true
+		}
+	}
+}
+
+// Where the mouse was clicked or the screen was tapped this tick
+func (pt *Pointer) press() (int, int, bool) {
+	if pt.clicked {
+		return pt.mouseX, pt.mouseY, true
+	}
+	if pt.tapped {
+		return pt.tapX, pt.tapY, true
+	}
+	return 0, 0, false
+}
+
+func onPauseButton(x, y int) bool {
+	px, py := pauseButton()
+	return math.Hypot(float64(x-px), float64(y-py)) <= pauseButtonRadius
+}
+
+func onZoomButton(x, y, by int) bool {
+	zx, zy := zoomButton(by)
+	return math.Hypot(float64(x-zx), float64(y-zy)) <= pauseButtonRadius
+}
+
+// Checks if a point is on one of the buttons at the top, rather than somewhere to drag the ship to
+func onButton(x, y int) bool {
+	return onPauseButton(x, y) || onZoomButton(x, y, -1) || onZoomButton(x, y, 1)
+}
+
+// Checks if the pause button was clicked or tapped this tick. A network client can not pause
+// the host, so for them the button leaves the game.
+func (g *Game) pausePressed() bool {
+	x, y, ok := g.pointer.press()
+	return ok && onPauseButton(x, y)
+}
+
+// Checks if the zoom in (1) or zoom out (-1) button was clicked or tapped this tick
+func (g *Game) zoomPressed(by int) bool {
+	x, y, ok := g.pointer.press()
+	return ok && g.mode == ModePlay && onZoomButton(x, y, by)
+}
+
+// Ship flown with a set of controls on this machine
+func (g *Game) shipFor(set ControlSet) *Player {
+	for _, p := range g.players {
+		if g.client != nil {
+			if p.id == g.client.PlayerID() {
+				return p
+			}
+			continue
+		}
+		if !p.remote && p.controls == set {
+			return p
+		}
+	}
+	return nil
+}
+
+// Adds the mouse and touch controls to the first player's controls. Clicking fires, dragging
+// flies the ship towards the mouse and the virtual stick works like a gamepad's.
+func (g *Game) addPointerState(set ControlSet, in *InputState) {
+
+	pt := &g.pointer
+	if set != ControlsSolo && set != ControlsPlayerOne {
+		return
+	}
+
+	if pt.stickActive {
+		x := float64(pt.stickX-pt.stickStartX) / touchStickRadius
+		y := float64(pt.stickY-pt.stickStartY) / touchStickRadius
+		if l := math.Hypot(x, y); l > 1 {
+			x, y = x/l, y/l
+		}
+		if math.Hypot(x, y) >= stickDeadZone {
+			in.StickX, in.StickY = x, y
+		}
+	}
+	if pt.fireHeld || (pt.clicked && pt.dragging) {
+		in.Fire = true
+	}
+
+	if pt.dragging {
+		if p := g.shipFor(set); p != nil {
+			sx, sy := g.camera.worldToScreen(p.shipXPos+shipWidth/2, p.shipYPos+shipHeight/2)
+			in.StickX = clampFloat((float64(pt.mouseX)-sx)/mouseFullSpeed, -1, 1)
+			in.StickY = clampFloat((float64(pt.mouseY)-sy)/mouseFullSpeed, -1, 1)
+		}
+	}
+}
+
+// Returns the visible menu item at a point on the screen, if there is one
+func (m *Menu) itemAt(x, y, count int) (int, bool) {
+	if y < m.top {
+		return 0, false
+	}
+	line := (y - m.top) / menuLineHeight
+	if m.shown > 0 && line >= m.shown {
+		return 0, false
+	}
+	i := m.first + line
+	return i, i < count
+}
+
+// Draws the pause and zoom buttons, and the stick and fire button for someone playing by touch
+func (g *Game) drawTouchControls(screen *ebiten.Image) {
+
+	if g.device != DevicePointer {
+		return
+	}
+	g.drawPauseButton(screen)
+	for _, by := range []int{-1, 1} {
+		label := "-"
+		if by > 0 {
+			label = "+"
+		}
+		zx, zy := zoomButton(by)
+		g.drawTouchButton(screen, zx, zy, pauseButtonRadius, label)
+	}
+
+	pt := &g.pointer
+	if !pt.touch {
+		return
+	}
+	fx, fy := fireButton()
+	g.drawTouchButton(screen, fx, fy, fireButtonRadius, tr("touch.fire"))
+
+	if pt.stickActive {
+		fillCircle(screen, float64(pt.stickStartX), float64(pt.stickStartY), touchStickRadius, touchButtonColour)
+		x, y := float64(pt.stickX-pt.stickStartX), float64(pt.stickY-pt.stickStartY)
+		if l := math.Hypot(x, y); l > touchStickRadius {
+			x, y = x/l*touchStickRadius, y/l*touchStickRadius
+		}
+		fillCircle(screen, float64(pt.stickStartX)+x, float64(pt.stickStartY)+y, 20, touchKnobColour)
+	}
+}
+
+// Draws the pause button, which is the leave button for a network client
+func (g *Game) drawPauseButton(screen *ebiten.Image) {
+	px, py := pauseButton()
+	label := "II"
+	if g.client != nil {
+		label = "X"
+	}
+	g.drawTouchButton(screen, px, py, pauseButtonRadius, label)
+}
+
+func (g *Game) drawTouchButton(screen *ebiten.Image, x, y, radius int, label string) {
+	fillCircle(screen, float64(x), float64(y), float64(radius), touchButtonColour)
+	drawText(screen, label, g.fonts.small, x-textWidth(g.fonts.small, label)/2, y-9, g.theme.text)
+}
+
+// Fills a circle, as a polygon with enough sides to look round
+func fillCircle(screen *ebiten.Image, x, y, r float64, c color.RGBA) {
+	const sides = 32
+	poly := make([]point, sides)
+	for i := range poly {
+		a := 2 * math.Pi * float64(i) / sides
+		poly[i] = point{x + r*math.Cos(a), y + r*math.Sin(a)}
+	}
+	fillPolygon(screen, poly, point{x, y}, c)
+}
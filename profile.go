@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// Folder and file the player data is kept in, inside the user config directory
+	profileDir  = "GoAsteroids"
+	profileFile = "profile.json"
+
+	// Number of high scores kept for each game type and level
+	maxHighScores = 5
+)
+
+// Player data saved between sessions
+type Profile struct {
+	HighScores []HighScore `json:"highScores"`
+
+	// Unlocked achievements with the time they were unlocked, and the progress towards the rest
+	Achievements map[string]time.Time `json:"achievements,omitempty"`
+	Progress     Progress             `json:"progress"`
+
+	// Statistics of the latest finished games, oldest first
+	History []SessionStats `json:"history,omitempty"`
+
+	// Folder name of the chosen theme
+	Theme string `json:"theme,omitempty"`
+
+	// Code of the chosen language, e.g. "fr", the system's language if empty
+	Language string `json:"language,omitempty"`
+}
+
+// High Score entry for a finished game
+type HighScore struct {
+	GameType GameType  `json:"gameType"`
+	Level    int       `json:"level"`
+	Players  int       `json:"players,omitempty"`
+	Score    int       `json:"score"`
+	Date     time.Time `json:"date"`
+}
+
+// Returns the path of the player data file
+func profilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileDir, profileFile), nil
+}
+
+// Loads the player data, starting a fresh profile if there is none yet
+func loadProfile() *Profile {
+	p := &Profile{}
+
+	path, err := profilePath()
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.profile_unsaveable", err))
+		return p
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("%s \n", tr("console.error_loading_player_data", err))
+		}
+		return p
+	}
+
+	if err := json.Unmarshal(data, p); err != nil {
+		fmt.Printf("%s \n", tr("console.error_reading_player_data", err))
+		return &Profile{}
+	}
+	p.relockUnreached()
+	return p
+}
+
+// Saves the player data to the user config directory
+func (p *Profile) save() error {
+	path, err := profilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Checks if a high score was set by the given game type, level and number of players
+func (s HighScore) matches(t GameType, level int, players int) bool {
+	// Scores saved before co-op was added have no player count
+	if s.Players == 0 {
+		s.Players = 1
+	}
+	return s.GameType == t && s.Level == level && s.Players == players
+}
+
+// Returns the high scores for a game type, level and number of players, best first
+func (p *Profile) highScores(t GameType, level int, players int) []HighScore {
+	var scores []HighScore
+	for _, s := range p.HighScores {
+		if s.matches(t, level, players) {
+			scores = append(scores, s)
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return t.better(scores[i].Score, scores[j].Score)
+	})
+	return scores
+}
+
+// Records a score, keeping only the best few for its game type, level and number of players.
+// Returns true if the score is a new best.
+func (p *Profile) recordHighScore(t GameType, level int, players int, score int) bool {
+	scores := p.highScores(t, level, players)
+	best := len(scores) == 0 || t.better(score, scores[0].Score)
+
+	scores = append(scores, HighScore{GameType: t, Level: level, Players: players, Score: score, Date: time.Now()})
+	sort.SliceStable(scores, func(i, j int) bool {
+		return t.better(scores[i].Score, scores[j].Score)
+	})
+	if len(scores) > maxHighScores {
+		scores = scores[:maxHighScores]
+	}
+
+	// Replace the old entries for this game type, level and number of players
+	kept := p.HighScores[:0]
+	for _, s := range p.HighScores {
+		if !s.matches(t, level, players) {
+			kept = append(kept, s)
+		}
+	}
+	p.HighScores = append(kept, scores...)
+
+	return best
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Settings screens, with the key of each one's title
+var settingsTitles = map[Mode]string{
+	ModeSettings:      "menu.settings",
+	ModeVideo:         "settings.video",
+	ModeAudio:         "settings.audio",
+	ModeControls:      "settings.controls",
+	ModeGameplay:      "settings.gameplay",
+	ModeLearning:      "settings.learning",
+	ModeAppearance:    "settings.appearance",
+	ModeAccessibility: "settings.accessibility",
+}
+
+// Opens the settings from the start or pause screen, going back there when done
+func (g *Game) openSettings() {
+	g.settingsReturn = g.mode
+	g.mode = ModeSettings
+}
+
+// Builds the menu of settings screens and the menu on each one
+func newSettingsMenus() map[Mode]*Menu {
+
+	toSettings := func(g *Game) { g.mode = ModeSettings }
+	back := MenuItem{label: fixedLabel("menu.back"), keys: []ebiten.Key{ebiten.KeyB}, action: toSettings}
+
+	// Option that turns a setting on and off
+	toggle := func(key string, k ebiten.Key, setting func(c *Config) *bool) MenuItem {
+		return MenuItem{
+			label: func(g *Game) string { return tr(key, onOff(*setting(g.config))) },
+			keys:  []ebiten.Key{k},
+			change: func(g *Game, by int) {
+				*setting(g.config) = !*setting(g.config)
+				g.configChanged()
+			},
+		}
+	}
+
+	// Option for a volume, changed in steps between 0 and 1
+	volume := func(key string, setting func(c *Config) *float64) MenuItem {
+		return MenuItem{
+			label: func(g *Game) string { return tr(key, *setting(g.config)*100) },
+			change: func(g *Game, by int) {
+				*setting(g.config) = clampFloat(*setting(g.config)+float64(by)*volumeStep, 0, 1)
+				g.configChanged()
+			},
+		}
+	}
+
+	pages := &Menu{back: func(g *Game) { g.mode = g.settingsReturn }}
+	for _, page := range []struct {
+		mode Mode
+		key  ebiten.Key
+	}{
+		{ModeVideo, ebiten.KeyV},
+		{ModeAudio, ebiten.KeyA},
+		{ModeControls, ebiten.KeyC},
+		{ModeGameplay, ebiten.KeyG},
+		{ModeLearning, ebiten.KeyL},
+		{ModeAppearance, ebiten.KeyT},
+		{ModeAccessibility, ebiten.KeyX},
+	} {
+		mode := page.mode
+		pages.items = append(pages.items, MenuItem{
+			label:  fixedLabel(settingsTitles[mode]),
+			keys:   []ebiten.Key{page.key},
+			action: func(g *Game) { g.mode = mode },
+		})
+	}
+	pages.items = append(pages.items, MenuItem{label: fixedLabel("menu.back"), keys: []ebiten.Key{ebiten.KeyB}, action: pages.back})
+
+	return map[Mode]*Menu{
+		ModeSettings: pages,
+		ModeVideo: {back: toSettings, items: []MenuItem{
+			{
+				label: func(g *Game) string { return tr("menu.window_size", fmt.Sprintf("x%g", g.config.Scale)) },
+				keys:  []ebiten.Key{ebiten.KeyW},
+				change: func(g *Game, by int) {
+					i := windowScaleIndex(g.config.Scale)
+					g.config.Scale = windowScales[(i+len(windowScales)+by)%len(windowScales)]
+					g.configChanged()
+				},
+			},
+			toggle("menu.fullscreen", ebiten.KeyF, func(c *Config) *bool { return &c.Fullscreen }),
+			{
+				label: func(g *Game) string { return tr("menu.resolution", screenSizeLabel()) },
+				keys:  []ebiten.Key{ebiten.KeyR},
+				change: func(g *Game, by int) {
+					i := screenResolutionIndex(g.config.Resolution)
+					g.config.Resolution = screenResolutions[(i+len(screenResolutions)+by)%len(screenResolutions)]
+					g.configChanged()
+				},
+			},
+			{
+				label: func(g *Game) string { return tr("menu.aspect", g.config.Aspect) },
+				keys:  []ebiten.Key{ebiten.KeyE},
+				change: func(g *Game, by int) {
+					g.config.Aspect = (g.config.Aspect + aspectModeCount + AspectMode(by)) % aspectModeCount
+					g.configChanged()
+				},
+			},
+			toggle("menu.vsync", ebiten.KeyS, func(c *Config) *bool { return &c.VSync }),
+			back,
+		}},
+		ModeAudio: {back: toSettings, items: []MenuItem{
+			volume("menu.volume", func(c *Config) *float64 { return &c.Volume }),
+			volume("menu.sound_volume", func(c *Config) *float64 { return &c.SoundVolume }),
+			volume("menu.music_volume", func(c *Config) *float64 { return &c.MusicVolume }),
+			toggle("menu.mute", ebiten.KeyM, func(c *Config) *bool { return &c.Muted }),
+			back,
+		}},
+		ModeControls: newControlsMenu(back),
+		ModeGameplay: {back: toSettings, items: []MenuItem{
+			{
+				label: func(g *Game) string { return tr("menu.asteroid_speed", g.config.AsteroidSpeed) },
+				change: func(g *Game, by int) {
+					speed := g.config.AsteroidSpeed + float64(by)*asteroidSpeedStep
+					if speed > maxAsteroidSpeed {
+						speed = minAsteroidSpeed
+					} else if speed < minAsteroidSpeed {
+						speed = maxAsteroidSpeed
+					}
+					g.config.AsteroidSpeed = speed
+					g.configChanged()
+				},
+			},
+			{
+				label: func(g *Game) string { return tr("menu.splits", g.config.Splits) },
+				change: func(g *Game, by int) {
+					count := maxSplits - minSplits + 1
+					g.config.Splits = minSplits + (g.config.Splits-minSplits+count+by)%count
+					g.configChanged()
+				},
+			},
+			toggle("menu.adaptive", ebiten.KeyD, func(c *Config) *bool { return &c.Adaptive }),
+			{
+				label: func(g *Game) string { return tr("menu.asteroid_style", g.config.AsteroidStyle) },
+				keys:  []ebiten.Key{ebiten.KeyV},
+				change: func(g *Game, by int) {
+					g.config.AsteroidStyle = (g.config.AsteroidStyle + asteroidStyleCount + AsteroidStyle(by)) % asteroidStyleCount
+					g.configChanged()
+				},
+			},
+			back,
+		}},
+		ModeLearning: {back: toSettings, items: []MenuItem{
+			{
+				label: func(g *Game) string { return tr("menu.strategy", g.strategy) },
+				keys:  []ebiten.Key{ebiten.KeyC},
+				change: func(g *Game, by int) {
+					s := (g.strategy + strategyCount + ConcurrencyStrategy(by)) % strategyCount
+					g.config.Strategy = s.key()
+					g.configChanged()
+				},
+			},
+			{
+				label: func(g *Game) string { return tr("menu.radar", g.config.RadarDetail) },
+				keys:  []ebiten.Key{ebiten.KeyR},
+				change: func(g *Game, by int) {
+					g.config.RadarDetail = (g.config.RadarDetail + radarDetailCount + RadarDetail(by)) % radarDetailCount
+					g.configChanged()
+				},
+			},
+			back,
+		}},
+		ModeAccessibility: {back: toSettings, items: []MenuItem{
+			{
+				label: func(g *Game) string { return tr("menu.palette", g.config.Palette) },
+				keys:  []ebiten.Key{ebiten.KeyC},
+				change: func(g *Game, by int) {
+					g.config.Palette = (g.config.Palette + paletteCount + Palette(by)) % paletteCount
+					g.configChanged()
+				},
+			},
+			toggle("menu.high_contrast", ebiten.KeyH, func(c *Config) *bool { return &c.HighContrast }),
+			{
+				label: func(g *Game) string { return tr("menu.game_speed", g.config.GameSpeed*100) },
+				keys:  []ebiten.Key{ebiten.KeyS},
+				change: func(g *Game, by int) {
+					speed := g.config.GameSpeed + float64(by)*gameSpeedStep
+					if speed > 1 {
+						speed = minGameSpeed
+					} else if speed < minGameSpeed {
+						speed = 1
+					}
+					g.config.GameSpeed = speed
+					g.configChanged()
+				},
+			},
+			toggle("menu.auto_fire", ebiten.KeyF, func(c *Config) *bool { return &c.AutoFire }),
+			toggle("menu.toggle_fire", ebiten.KeyT, func(c *Config) *bool { return &c.ToggleFire }),
+			toggle("menu.reduce_flashing", ebiten.KeyR, func(c *Config) *bool { return &c.ReduceFlashing }),
+			{
+				label: func(g *Game) string { return tr("menu.screen_shake", g.config.ScreenShake*100) },
+				keys:  []ebiten.Key{ebiten.KeyK},
+				change: func(g *Game, by int) {
+					shake := g.config.ScreenShake + float64(by)*screenShakeStep
+					if shake > 1 {
+						shake = 0
+					} else if shake < 0 {
+						shake = 1
+					}
+					g.config.ScreenShake = shake
+					g.configChanged()
+				},
+			},
+			toggle("menu.large_hud", ebiten.KeyL, func(c *Config) *bool { return &c.LargeHUD }),
+			back,
+		}},
+		ModeAppearance: {back: toSettings, items: []MenuItem{
+			{
+				label:  func(g *Game) string { return tr("menu.theme", g.theme.manifest.Name) },
+				keys:   []ebiten.Key{ebiten.KeyT},
+				change: func(g *Game, by int) { g.changeTheme(by) },
+			},
+			{
+				label:  func(g *Game) string { return tr("menu.language", catalog.name()) },
+				keys:   []ebiten.Key{ebiten.KeyL},
+				change: func(g *Game, by int) { g.changeLocale(by) },
+			},
+			// Look again for themes, e.g. after copying a new one into the themes folder
+			{label: fixedLabel("menu.find_themes"), keys: []ebiten.Key{ebiten.KeyR}, action: func(g *Game) {
+				g.loadTheme()
+				g.notify(tr("notify.themes_found", len(g.themes)))
+			}},
+			back,
+		}},
+	}
+}
+
+// Builds the controls screen, with a line for each action in the set of controls shown
+func newControlsMenu(back MenuItem) *Menu {
+
+	m := &Menu{back: back.action, items: []MenuItem{{
+		label: func(g *Game) string { return tr("menu.key_set", g.remap.set) },
+		keys:  []ebiten.Key{ebiten.KeyK},
+		change: func(g *Game, by int) {
+			g.remap.set = (g.remap.set + controlSetCount + ControlSet(by)) % controlSetCount
+		},
+	}}}
+
+	for a := Action(0); a < actionCount; a++ {
+		a := a
+		m.items = append(m.items, MenuItem{
+			label: func(g *Game) string {
+				if g.remap.waiting && g.remap.action == a {
+					return tr("menu.binding", a, "...")
+				}
+				return tr("menu.binding", a, g.config.Keys[g.remap.set].label(a))
+			},
+			action: func(g *Game) {
+				g.remap.action = a
+				g.remap.waiting = true
+			},
+			// The players in co-op share the one player keys for everything but their ships
+			hidden: func(g *Game) bool { return !a.ship() && g.remap.set != ControlsSolo },
+		})
+	}
+
+	// A single player can use any gamepad, the players in co-op choose one each
+	m.items = append(m.items, MenuItem{
+		label:  func(g *Game) string { return tr("menu.gamepad", g.padName(g.remap.set)) },
+		keys:   []ebiten.Key{ebiten.KeyG},
+		change: func(g *Game, by int) { g.changePad(g.remap.set, by) },
+		hidden: func(g *Game) bool { return g.remap.set == ControlsSolo },
+	})
+
+	m.items = append(m.items,
+		MenuItem{label: fixedLabel("menu.reset_keys"), keys: []ebiten.Key{ebiten.KeyR}, action: func(g *Game) {
+			g.config.Keys[g.remap.set] = defaultBindings()[g.remap.set]
+			g.configChanged()
+		}},
+		back,
+	)
+	return m
+}
+
+// Switches to the next or previous theme, changes are applied straight away
+func (g *Game) changeTheme(by int) {
+	if len(g.themes) == 0 {
+		return
+	}
+	i := (g.themeIndex() + len(g.themes) + by) % len(g.themes)
+	g.useTheme(g.themes[i])
+}
+
+// Draws the settings screen being shown, with its title and menu
+func (g *Game) drawSettings(screen *ebiten.Image) {
+
+	drawCentredText(screen, strings.ToUpper(tr(settingsTitles[g.mode])), g.fonts.heading, 50, g.theme.text)
+	g.menus[g.mode].draw(g, screen, 120)
+
+	switch g.mode {
+	case ModeVideo:
+		drawCentredText(screen, g.config.Aspect.description(), g.fonts.small, 340, colourHint)
+	case ModeControls:
+		if g.remap.waiting {
+			drawCentredText(screen, tr("settings.press_key", g.remap.action), g.fonts.small, fromBottom(70), g.theme.highlight)
+			return
+		}
+	case ModeGameplay:
+		drawCentredText(screen, tr("settings.next_game"), g.fonts.small, 340, colourHint)
+	case ModeLearning:
+		drawCentredText(screen, g.strategy.description(), g.fonts.small, 240, colourHint)
+	case ModeAppearance:
+		g.drawThemePreview(screen)
+		drawCentredText(screen, tr("settings.help"), g.fonts.small, fromBottom(45), colourHint)
+		return
+	}
+	drawCentredText(screen, g.prompt(tr("settings.page_help"), tr("pad.page_help", padLabel(ActionBack)), tr("pointer.page_help")), g.fonts.small, fromBottom(45), colourHint)
+}
+
+// Draws the theme's description and a preview of its art
+func (g *Game) drawThemePreview(screen *ebiten.Image) {
+
+	t := g.theme
+	about := t.manifest.Description
+	if t.manifest.Author != "" {
+		about += tr("settings.theme_author", t.manifest.Author)
+	}
+	drawCentredText(screen, about, g.fonts.small, 260, colourHint)
+
+	drawOptions := &ebiten.DrawImageOptions{}
+	drawOptions.GeoM.Translate(float64(pageLeft()+220), 305)
+	screen.DrawImage(g.ship, drawOptions)
+	drawOptions.GeoM.Translate(120, -10)
+	screen.DrawImage(g.asteroidImage, drawOptions)
+	drawOptions.GeoM.Translate(180, 25)
+	screen.DrawImage(g.miniAsteroidImage, drawOptions)
+
+	if path, err := userThemesPath(); err == nil {
+		drawCentredText(screen, tr("settings.themes_path"), g.fonts.small, 470, colourHint)
+		drawCentredText(screen, path, g.fonts.small, 490, colourHint)
+	}
+}
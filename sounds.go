@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// The default sounds and music are generated when the game starts, so they are
+// built into the game and need no files. Samples are 16 bit stereo, as ebiten plays them.
+
+const sampleRate = 44100
+
+// Sound effects
+type Sound int
+
+const (
+	SoundFire Sound = iota
+	SoundHit
+	SoundSplit
+	SoundDamage
+	SoundMenu
+
+	soundCount
+)
+
+// Names of the sounds and music in theme manifests
+var (
+	soundNames = [soundCount]string{"fire", "hit", "split", "damage", "menu"}
+	musicNames = [musicCount]string{"", "menuMusic", "playMusic", "resultsMusic"}
+)
+
+// Background music, one loop for each kind of screen
+type Music int
+
+const (
+	MusicNone Music = iota
+	MusicMenu
+	MusicPlay
+	MusicResults
+
+	musicCount
+)
+
+// Generates the samples for a sound effect
+func generateSound(s Sound) []float64 {
+	switch s {
+	case SoundFire:
+		// Square wave falling from 880Hz to 220Hz
+		return synth(0.12, func(t, d float64) float64 {
+			return 0.3 * square(t, 880-660*d) * (1 - d)
+		})
+	case SoundHit:
+		// Burst of noise that dies away, smoothed to sound like a rumble
+		smooth := 0.0
+		return synth(0.4, func(t, d float64) float64 {
+			smooth += (rand.Float64()*2 - 1 - smooth) * 0.2
+			return 0.8 * smooth * math.Pow(1-d, 2)
+		})
+	case SoundSplit:
+		// Two quick rising blips
+		return synth(0.1, func(t, d float64) float64 {
+			freq := 600.0
+			if d > 0.5 {
+				freq = 900
+			}
+			return 0.25 * math.Sin(2*math.Pi*freq*t) * (1 - math.Mod(d*2, 1))
+		})
+	case SoundDamage:
+		// Low buzz with some noise
+		return synth(0.15, func(t, d float64) float64 {
+			return (0.25*square(t, 110) + 0.1*(rand.Float64()*2-1)) * (1 - d)
+		})
+	case SoundMenu:
+		return synth(0.06, func(t, d float64) float64 {
+			return 0.25 * math.Sin(2*math.Pi*660*t) * (1 - d)
+		})
+	}
+	return nil
+}
+
+// Notes for each music loop as frequencies in Hz, 0 for a rest, and the length of each note in seconds
+var musicScores = map[Music]struct {
+	notes []float64
+	beat  float64
+}{
+	MusicMenu: {
+		notes: []float64{220, 261.63, 329.63, 261.63, 196, 246.94, 293.66, 246.94, 174.61, 220, 261.63, 220, 164.81, 207.65, 246.94, 207.65},
+		beat:  0.3,
+	},
+	MusicPlay: {
+		notes: []float64{110, 0, 110, 130.81, 110, 0, 98, 0, 110, 0, 110, 146.83, 130.81, 0, 98, 87.31},
+		beat:  0.15,
+	},
+	MusicResults: {
+		notes: []float64{261.63, 329.63, 392, 523.25, 392, 329.63, 261.63, 0},
+		beat:  0.35,
+	},
+}
+
+// Generates one loop of background music
+func generateMusic(m Music) []float64 {
+	score, ok := musicScores[m]
+	if !ok {
+		return nil
+	}
+
+	var samples []float64
+	for _, freq := range score.notes {
+		note := synth(score.beat, func(t, d float64) float64 {
+			if freq == 0 {
+				return 0
+			}
+			// Soft attack and release so the notes do not click
+			envelope := math.Min(1, math.Min(d*20, (1-d)*5))
+			return 0.15 * triangle(t, freq) * envelope
+		})
+		samples = append(samples, note...)
+	}
+	return samples
+}
+
+// Calls f for every sample of a sound lasting the given seconds.
+// f is given the time in seconds and how far through the sound it is, from 0 to 1.
+func synth(seconds float64, f func(t, d float64) float64) []float64 {
+	n := int(seconds * sampleRate)
+	samples := make([]float64, n)
+	for i := range samples {
+		t := float64(i) / sampleRate
+		samples[i] = f(t, float64(i)/float64(n))
+	}
+	return samples
+}
+
+func square(t, freq float64) float64 {
+	if math.Mod(t*freq, 1) < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+func triangle(t, freq float64) float64 {
+	return 4*math.Abs(math.Mod(t*freq, 1)-0.5) - 1
+}
+
+// Converts samples between -1 and 1 into 16 bit little endian stereo
+func pcm(samples []float64) []byte {
+	b := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		v := int16(clampFloat(s, -1, 1) * math.MaxInt16)
+		binary.LittleEndian.PutUint16(b[i*4:], uint16(v))
+		binary.LittleEndian.PutUint16(b[i*4+2:], uint16(v))
+	}
+	return b
+}
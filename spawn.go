@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// Closest an asteroid may be placed to a ship, and to another asteroid, centre to centre
+	shipClearance    = 220.0
+	asteroidSpacing  = 130.0
+	minSpacingFactor = 0.5
+
+	// Candidates tried around each placed asteroid before giving up on it (Bridson's algorithm)
+	poissonCandidates = 30
+)
+
+// Asteroids entering from the screen edges at a set time into a level
+type Wave struct {
+	seconds   int
+	asteroids int
+}
+
+// Timed waves for each level, in the order they arrive
+var levelWaves = map[int][]Wave{
+	1: {{seconds: 20, asteroids: 2}},
+	2: {{seconds: 20, asteroids: 3}, {seconds: 45, asteroids: 3}},
+	3: {{seconds: 15, asteroids: 3}, {seconds: 35, asteroids: 4}, {seconds: 60, asteroids: 5}},
+}
+
+// Point in the world, the centre of an asteroid
+type point struct {
+	x, y float64
+}
+
+func (p point) dist(o point) float64 {
+	return math.Hypot(p.x-o.x, p.y-o.y)
+}
+
+// Returns the centre of every ship in the game
+func (g *Game) shipCentres() []point {
+	var ships []point
+	for _, p := range g.players {
+		ships = append(ships, point{p.shipXPos + shipWidth/2, p.shipYPos + shipHeight/2})
+	}
+	return ships
+}
+
+// Checks if a point is far enough from every ship
+func clearOfShips(p point, ships []point) bool {
+	for _, s := range ships {
+		if p.dist(s) < shipClearance {
+			return false
+		}
+	}
+	return true
+}
+
+// Chooses where n asteroids start, never near a ship and spread apart using Poisson-disk sampling.
+// If the field is too crowded to fit them the spacing between asteroids is reduced, but never the
+// clearance around the ships.
+func safePositions(n int, ships []point) []point {
+
+	for spacing := asteroidSpacing; ; spacing *= 0.95 {
+		if spacing < asteroidSpacing*minSpacingFactor {
+			spacing = 0
+		}
+		// Sampling is random, so a few attempts are made before the spacing is reduced
+		for attempt := 0; attempt < 5; attempt++ {
+			points := poissonDisk(n, spacing, ships)
+			if len(points) == n || spacing == 0 {
+				return points
+			}
+		}
+	}
+}
+
+// Bridson's algorithm - each new point is tried in a ring around an already placed point,
+// with a grid so only nearby points have to be checked.
+func poissonDisk(n int, spacing float64, ships []point) []point {
+
+	minX, maxX := float64(asteroidWidth/2), float64(worldWidth-asteroidWidth/2)
+	minY, maxY := float64(asteroidHeight/2), float64(worldHeight-asteroidHeight/2)
+	inField := func(p point) bool {
+		return p.x >= minX && p.x <= maxX && p.y >= minY && p.y <= maxY
+	}
+
+	// Any spot clear of the ships will do when the asteroids can touch
+	if spacing == 0 {
+		var points []point
+		for tries := 0; len(points) < n && tries < 1000*n; tries++ {
+			p := point{minX + rand.Float64()*(maxX-minX), minY + rand.Float64()*(maxY-minY)}
+			if clearOfShips(p, ships) {
+				points = append(points, p)
+			}
+		}
+		return points
+	}
+
+	cell := spacing / math.Sqrt2
+	cols, rows := int((maxX-minX)/cell)+1, int((maxY-minY)/cell)+1
+	grid := make([]int, cols*rows)
+	for i := range grid {
+		grid[i] = -1
+	}
+	cellOf := func(p point) (int, int) {
+		return int((p.x - minX) / cell), int((p.y - minY) / cell)
+	}
+
+	var points, active []point
+	fits := func(p point) bool {
+		if !inField(p) || !clearOfShips(p, ships) {
+			return false
+		}
+		cx, cy := cellOf(p)
+		for y := cy - 2; y <= cy+2; y++ {
+			for x := cx - 2; x <= cx+2; x++ {
+				if x < 0 || y < 0 || x >= cols || y >= rows || grid[y*cols+x] < 0 {
+					continue
+				}
+				if p.dist(points[grid[y*cols+x]]) < spacing {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	place := func(p point) {
+		cx, cy := cellOf(p)
+		grid[cy*cols+cx] = len(points)
+		points = append(points, p)
+		active = append(active, p)
+	}
+
+	// First asteroid anywhere clear of the ships
+	for tries := 0; tries < 1000; tries++ {
+		p := point{minX + rand.Float64()*(maxX-minX), minY + rand.Float64()*(maxY-minY)}
+		if fits(p) {
+			place(p)
+			break
+		}
+	}
+
+	for len(active) > 0 && len(points) < n {
+		i := rand.Intn(len(active))
+		from := active[i]
+
+		placed := false
+		for k := 0; k < poissonCandidates; k++ {
+			angle := rand.Float64() * 2 * math.Pi
+			r := spacing * (1 + rand.Float64())
+			p := point{from.x + r*math.Cos(angle), from.y + r*math.Sin(angle)}
+			if fits(p) {
+				place(p)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			active = append(active[:i], active[i+1:]...)
+		}
+	}
+
+	// Shuffle so the asteroids are not numbered in the order they spread out
+	rand.Shuffle(len(points), func(i, j int) {
+		points[i], points[j] = points[j], points[i]
+	})
+	return points
+}
+
+// Chooses a point just off an edge of the world, away from the ships, and a velocity that carries the asteroid onto the screen
+func edgeEntry(ships []point) (point, float64, float64) {
+
+	var p point
+	var vx, vy float64
+	for tries := 0; tries < 20; tries++ {
+		vx, vy = float64(2*rand.Intn(2)-1), 1
+		switch rand.Intn(3) {
+		case 0: // Top
+			p = point{float64(asteroidWidth/2 + rand.Intn(worldWidth-asteroidWidth)), -float64(asteroidHeight / 2)}
+			// Head inward near the sides, or the asteroid is off the side before it is fully on screen
+			if margin := float64(asteroidWidth/2 + asteroidHeight); p.x < margin {
+				vx = 1
+			} else if p.x > float64(worldWidth)-margin {
+				vx = -1
+			}
+		case 1: // Left
+			p = point{-float64(asteroidWidth / 2), float64(asteroidHeight/2 + rand.Intn(worldHeight/2))}
+			vx = 1
+		case 2: // Right
+			p = point{float64(worldWidth + asteroidWidth/2), float64(asteroidHeight/2 + rand.Intn(worldHeight/2))}
+			vx = -1
+		}
+		if clearOfShips(p, ships) {
+			break
+		}
+	}
+	return p, vx, vy
+}
+
+// Sends in any wave due at this point in the level
+func (g *Game) updateWaves() {
+
+	waves := levelWaves[g.level]
+	if g.gameType == TypeSurvival || g.gameType == TypeVersus || g.wave >= len(waves) {
+		return
+	}
+	if g.ticks < waves[g.wave].seconds*ticksPerSecond {
+		return
+	}
+
+	w := waves[g.wave]
+	g.wave++
+	for i := 0; i < w.asteroids; i++ {
+		spawnAsteroid(g)
+	}
+	g.notify(tr("notify.wave", g.wave, w.asteroids))
+	fmt.Printf("%s \n", tr("console.wave", g.wave, len(waves), w.asteroids))
+}
+
+// Checks if there are waves still to come this level
+func (g *Game) wavesPending() bool {
+	return g.gameType != TypeSurvival && g.gameType != TypeVersus && g.wave < len(levelWaves[g.level])
+}
+
+// Generates a new large asteroid entering from a screen edge using a Go Routine
+func spawnAsteroid(g *Game) {
+
+	if AsteroidsInGame >= maxDifficulty {
+		return
+	}
+
+	var wg sync.WaitGroup
+	var a *Asteroid
+	ships := g.shipCentres()
+
+	wg.Add(1)
+	go func() {
+		atomic.AddUint32(&generationGoroutines, 1)
+		w := asteroidWidth
+		h := asteroidHeight
+		p, vx, vy := edgeEntry(ships)
+		a = &Asteroid{
+			id:       atomic.AddUint32(&asteroidIDs, 1),
+			width:    w,
+			height:   h,
+			x:        p.x - float64(w/2),
+			y:        p.y - float64(h/2),
+			vx:       vx,
+			vy:       vy,
+			angle:    float64(rand.Intn(maxAngle)),
+			entering: true,
+		}
+		fmt.Println(tr("console.spawn_goroutine"))
+		wg.Done()
+	}()
+	wg.Wait()
+
+	g.asteroids.asteroidsList = append(g.asteroids.asteroidsList[:AsteroidsInGame], a)
+	AsteroidsInGame = AsteroidsInGame + 1
+}
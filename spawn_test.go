@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestEdgeAsteroidsComeOnScreen(t *testing.T) {
+	for i := 0; i < 5000; i++ {
+		p, vx, vy := edgeEntry(nil)
+		a := &Asteroid{
+			width:    asteroidWidth,
+			height:   asteroidHeight,
+			x:        p.x - float64(asteroidWidth/2),
+			y:        p.y - float64(asteroidHeight/2),
+			vx:       vx,
+			vy:       vy,
+			entering: true,
+		}
+
+		for tick := 0; a.entering; tick++ {
+			if tick > worldWidth+worldHeight {
+				t.Fatalf("asteroid from %v moving %v,%v still entering at %.0f,%.0f", p, vx, vy, a.x, a.y)
+			}
+			a.Update()
+		}
+		for tick := 0; tick < asteroidWidth+asteroidHeight; tick++ {
+			a.Update()
+		}
+		if a.x < 0 || a.y < 0 || a.x > float64(worldWidth-a.width) || a.y > float64(worldHeight-a.height) {
+			t.Fatalf("asteroid from %v moving %v,%v left the world at %.0f,%.0f", p, vx, vy, a.x, a.y)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Spectator Stream
+//
+// A game started with -stream serves its live state on a local TCP port so a
+// second screen can watch it. Each tick every connected viewer is sent one
+// line of JSON - a "snapshot" NetMessage (see network.go) with Version set -
+// holding the ships, rockets, asteroids and the concurrency radar counters.
+// Viewers do not send anything. A viewer too slow to keep up misses ticks.
+//
+// Run the game with -spectate to watch a stream, or read it with any tool
+// that can open a TCP connection, e.g. "nc localhost 7779".
+
+// Port the spectator stream is served on when none is given
+const defaultStreamPort = "7779"
+
+// Serves the live game state to spectators
+type SpectatorServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	viewers map[net.Conn]chan []byte
+}
+
+// Starts serving the spectator stream on the given address, e.g. "localhost:7779"
+func NewSpectatorServer(addr string) (*SpectatorServer, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &SpectatorServer{
+		listener: l,
+		viewers:  make(map[net.Conn]chan []byte),
+	}
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Returns the address the stream is served on
+func (s *SpectatorServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stops the stream and disconnects every viewer
+func (s *SpectatorServer) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.viewers {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *SpectatorServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		out := make(chan []byte, netSendBuffer)
+		s.mu.Lock()
+		s.viewers[conn] = out
+		s.mu.Unlock()
+		fmt.Printf("%s \n", tr("console.spectator_connected", conn.RemoteAddr()))
+
+		go s.writeLoop(conn, out)
+	}
+}
+
+// Writes each tick's state to a viewer until it disconnects
+func (s *SpectatorServer) writeLoop(conn net.Conn, out chan []byte) {
+	w := bufio.NewWriter(conn)
+	for line := range out {
+		if _, err := w.Write(line); err != nil {
+			break
+		}
+		if err := w.Flush(); err != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	if _, ok := s.viewers[conn]; ok {
+		delete(s.viewers, conn)
+		close(out)
+	}
+	s.mu.Unlock()
+	conn.Close()
+	fmt.Printf("%s \n", tr("console.spectator_left", conn.RemoteAddr()))
+}
+
+// Sends a snapshot to every viewer
+func (s *SpectatorServer) publish(snapshot Snapshot) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.viewers) == 0 {
+		return
+	}
+
+	line, err := json.Marshal(NetMessage{Type: msgSnapshot, Version: protocolVersion, Snapshot: &snapshot})
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.error_encoding_stream", err))
+		return
+	}
+	line = append(line, '\n')
+
+	for _, out := range s.viewers {
+		select {
+		case out <- line:
+		default:
+			// Viewer is not keeping up, skip this tick
+		}
+	}
+}
+
+// Connects to a spectator stream - the client only receives snapshots
+func DialSpectator(addr string) (*NetClient, error) {
+	conn, err := net.DialTimeout("tcp", addr, helloTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &NetClient{conn: conn, spectator: true}
+	go c.readLoop(json.NewDecoder(bufio.NewReader(conn)))
+	return c, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// Number of finished games kept in the history
+	maxHistory = 100
+
+	// Number of games shown in the trend chart and compared for the trends
+	trendGames = 20
+	trendSplit = 5
+)
+
+// Chart bars for games won and lost, from the palette in use (see useColours)
+var (
+	chartWon  = palettes[PaletteStandard].chartWon
+	chartLost = palettes[PaletteStandard].chartLost
+)
+
+// Statistics for one game, kept in the history once it is finished
+type SessionStats struct {
+	Date     time.Time `json:"date"`
+	GameType GameType  `json:"gameType"`
+	Level    int       `json:"level"`
+	Players  int       `json:"players"`
+	Strategy string    `json:"strategy"`
+	Won      bool      `json:"won"`
+	Millis   int       `json:"millis"`
+	Score    int       `json:"score"`
+
+	Shots         int `json:"shots"`
+	Hits          int `json:"hits"`
+	DamageTaken   int `json:"damageTaken"`
+	Asteroids     int `json:"asteroids"`
+	MiniAsteroids int `json:"miniAsteroids"`
+
+	GenerationGoroutines uint32 `json:"generationGoroutines"`
+	UpdateGoroutines     uint32 `json:"updateGoroutines"`
+
+	// Changes made by adaptive difficulty, with the reason for each
+	Adjustments []string `json:"adjustments,omitempty"`
+}
+
+// Percentage of shots that hit something, 0 when nothing was fired
+func (s SessionStats) accuracy() float64 {
+	if s.Shots == 0 {
+		return 0
+	}
+	return float64(s.Hits) * 100 / float64(s.Shots)
+}
+
+// Adds another game's statistics to these, used for the totals
+func (s *SessionStats) add(o SessionStats) {
+	s.Millis += o.Millis
+	s.Score += o.Score
+	s.Shots += o.Shots
+	s.Hits += o.Hits
+	s.DamageTaken += o.DamageTaken
+	s.Asteroids += o.Asteroids
+	s.MiniAsteroids += o.MiniAsteroids
+	s.GenerationGoroutines += o.GenerationGoroutines
+	s.UpdateGoroutines += o.UpdateGoroutines
+}
+
+// Adds a finished game to the history, dropping the oldest once it is full
+func (p *Profile) recordSession(s SessionStats) {
+	p.History = append(p.History, s)
+	if len(p.History) > maxHistory {
+		p.History = p.History[len(p.History)-maxHistory:]
+	}
+}
+
+// Fills in how the game ended and adds it to the history
+func (g *Game) recordSession(mode Mode) {
+
+	s := &g.session
+	s.Date = time.Now()
+	s.GameType = g.gameType
+	s.Level = g.level
+	s.Players = len(g.players)
+	s.Strategy = g.strategy.key()
+	s.Won = mode == ModeWon || (mode == ModeResult && g.localWinner())
+	s.Millis = ticksToMillis(g.ticks)
+	s.Score = g.totalScore()
+	s.GenerationGoroutines = generationGoroutines
+	s.UpdateGoroutines = updateGoroutines
+	s.Adjustments = g.difficulty.log
+
+	g.profile.recordSession(*s)
+
+	fmt.Printf("%s \n", tr("console.game_summary",
+		s.Shots, s.Hits, s.accuracy(), s.DamageTaken, s.Asteroids, s.MiniAsteroids, float64(s.Millis)/1000))
+}
+
+// Returns the average accuracy of a run of games
+func averageAccuracy(history []SessionStats) float64 {
+	var total SessionStats
+	for _, s := range history {
+		total.add(s)
+	}
+	return total.accuracy()
+}
+
+// Describes how a value has moved between two averages
+func trend(before, after float64) string {
+	switch {
+	case after > before+1:
+		return tr("stats.trend_up")
+	case after < before-1:
+		return tr("stats.trend_down")
+	}
+	return tr("stats.trend_steady")
+}
+
+// Draws the statistics screen with the totals and recent trends
+func (g *Game) drawStats(screen *ebiten.Image) {
+
+	history := g.profile.History
+	drawCentredText(screen, tr("stats.title"), g.fonts.heading, 30, g.theme.text)
+
+	if len(history) == 0 {
+		drawCentredText(screen, tr("stats.none"), g.fonts.body, 280, g.theme.text)
+		drawCentredText(screen, g.prompt(tr("screen.back", g.config.Keys[ControlsSolo].label(ActionBack)), tr("pad.back", padLabel(ActionBack)), tr("pointer.back")), g.fonts.small, fromBottom(30), colourHint)
+		return
+	}
+
+	var total SessionStats
+	won := 0
+	for _, s := range history {
+		total.add(s)
+		if s.Won {
+			won++
+		}
+	}
+
+	lines := []string{
+		tr("stats.games", len(history), won),
+		tr("stats.time", time.Duration(total.Millis)*time.Millisecond),
+		tr("stats.shots", total.Shots),
+		tr("stats.hits", total.Hits),
+		tr("stats.accuracy", total.accuracy()),
+		tr("stats.damage", total.DamageTaken),
+		tr("stats.asteroids", total.Asteroids),
+		tr("stats.mini_asteroids", total.MiniAsteroids),
+		tr("stats.goroutines", total.GenerationGoroutines, total.UpdateGoroutines),
+	}
+	for i, l := range lines {
+		g.print(screen, l, pageLeft()+100, 80+i*20)
+	}
+
+	// Compare the latest games with the ones before them
+	if len(history) >= 2*trendSplit {
+		recent := history[len(history)-trendSplit:]
+		before := history[len(history)-2*trendSplit : len(history)-trendSplit]
+		accuracy := trend(averageAccuracy(before), averageAccuracy(recent))
+
+		var damageBefore, damageRecent SessionStats
+		for i := range recent {
+			damageBefore.add(before[i])
+			damageRecent.add(recent[i])
+		}
+		damage := trend(float64(damageBefore.DamageTaken)/trendSplit, float64(damageRecent.DamageTaken)/trendSplit)
+
+		g.print(screen, tr("stats.trends", trendSplit, accuracy, damage), pageLeft()+100, 270)
+	}
+
+	// Accuracy of the latest games as a bar chart, oldest on the left
+	start := len(history) - trendGames
+	if start < 0 {
+		start = 0
+	}
+	recent := history[start:]
+
+	g.print(screen, tr("stats.chart", len(recent)), pageLeft()+100, 310)
+	ebitenutil.DrawRect(screen, float64(pageLeft()+100), 530, 600, 1, color.White)
+	for i, s := range recent {
+		h := s.accuracy() * 2
+		barColour := chartLost
+		if s.Won {
+			barColour = chartWon
+		}
+		ebitenutil.DrawRect(screen, float64(pageLeft()+100+i*30), 530-h, 24, h, barColour)
+	}
+
+	drawCentredText(screen, g.prompt(tr("screen.back", g.config.Keys[ControlsSolo].label(ActionBack)), tr("pad.back", padLabel(ActionBack)), tr("pointer.back")), g.fonts.small, fromBottom(30), colourHint)
+}
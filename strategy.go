@@ -0,0 +1,149 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Concurrency Strategy used to update the asteroids each tick
+type ConcurrencyStrategy int
+
+const (
+	// One Go routine is started for every asteroid, every tick
+	StrategyGoroutinePerAsteroid ConcurrencyStrategy = 0
+
+	// A Go routine for each CPU core is started every tick, sharing the asteroids between them
+	StrategyWorkerPool ConcurrencyStrategy = 1
+
+	// Every asteroid is updated one after another, without any Go routines
+	StrategySequential ConcurrencyStrategy = 2
+
+	strategyCount = 3
+)
+
+func (s ConcurrencyStrategy) String() string {
+	return tr("strategy." + s.messageKey())
+}
+
+// Explanation of the strategy shown in the settings
+func (s ConcurrencyStrategy) description() string {
+	return tr("strategy." + s.messageKey() + ".description")
+}
+
+func (s ConcurrencyStrategy) messageKey() string {
+	switch s {
+	case StrategyWorkerPool:
+		return "worker_pool"
+	case StrategySequential:
+		return "sequential"
+	}
+	return "goroutine_per_asteroid"
+}
+
+// Name saved with the player data, which must not change
+func (s ConcurrencyStrategy) key() string {
+	switch s {
+	case StrategyWorkerPool:
+		return "worker-pool"
+	case StrategySequential:
+		return "sequential"
+	}
+	return "goroutine-per-asteroid"
+}
+
+// Returns the strategy saved with a name, the default if the name is not known
+func strategyFromKey(key string) ConcurrencyStrategy {
+	for s := ConcurrencyStrategy(0); s < strategyCount; s++ {
+		if s.key() == key {
+			return s
+		}
+	}
+	return StrategyGoroutinePerAsteroid
+}
+
+// Updates every asteroid in the list for one tick
+func (s ConcurrencyStrategy) update(asteroids []*Asteroid) {
+	switch s {
+	case StrategyWorkerPool:
+		updateWithWorkerPool(asteroids)
+	case StrategySequential:
+		for _, a := range asteroids {
+			a.Update()
+		}
+	default:
+		updateWithGoroutineEach(asteroids)
+	}
+}
+
+func updateWithGoroutineEach(asteroids []*Asteroid) {
+
+	var wg sync.WaitGroup
+
+	for i := range asteroids {
+
+		wg.Add(1)
+		go func(i int) {
+			atomic.AddUint32(&updateGoroutines, 1)
+			asteroids[i].Update()
+			wg.Done()
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// Each worker takes the next asteroid from a channel until there are none left
+func updateWithWorkerPool(asteroids []*Asteroid) {
+
+	workers := runtime.NumCPU()
+	if workers > len(asteroids) {
+		workers = len(asteroids)
+	}
+
+	next := make(chan *Asteroid, len(asteroids))
+	for _, a := range asteroids {
+		next <- a
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			atomic.AddUint32(&updateGoroutines, 1)
+			for a := range next {
+				a.Update()
+			}
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+}
+
+// How much of the concurrency radar is shown during play
+type RadarDetail int
+
+const (
+	// Only the health boxes
+	RadarOff RadarDetail = 0
+
+	// The number of asteroids and mini asteroids
+	RadarBasic RadarDetail = 1
+
+	// The asteroids, the Go routines used for them and the strategy
+	RadarFull RadarDetail = 2
+
+	radarDetailCount = 3
+)
+
+func (r RadarDetail) String() string {
+	switch r {
+	case RadarOff:
+		return tr("radar.off")
+	case RadarBasic:
+		return tr("radar.basic")
+	}
+	return tr("radar.full")
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+// Themes that come with the game, one folder each
+//
+//go:embed themes
+var embeddedThemes embed.FS
+
+const (
+	// Folder of themes, inside the user config directory and inside the game
+	themesDir = "themes"
+
+	// File in each theme folder describing the theme
+	themeManifest = "theme.json"
+
+	// Theme used when none is chosen, drawn with the built in images, colours and sounds
+	defaultTheme = "classic"
+)
+
+// Contents of theme.json. Images are not listed - any image in the theme folder with the
+// same path as a built in one, e.g. GUI/GameAssets/ship.png, replaces it.
+type ThemeManifest struct {
+	Name        string `json:"name"`
+	Author      string `json:"author,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Colours as "#rrggbb" or "#rrggbbaa", for "background", "stars", "rocket", "asteroidOutline",
+	// "asteroidFill", and "text" and "highlight" for the menus
+	Colours map[string]string `json:"colours,omitempty"`
+
+	// WAV files in the theme folder replacing sounds ("fire", "hit", "split", "damage", "menu")
+	// and music ("menuMusic", "playMusic", "resultsMusic")
+	Sounds map[string]string `json:"sounds,omitempty"`
+}
+
+// Theme is a set of art, colours and sounds the game can be drawn and played with
+type Theme struct {
+	key      string
+	manifest ThemeManifest
+
+	// Theme folder and where it was found, nil for the default theme
+	files  fs.FS
+	source string
+
+	background      color.RGBA
+	stars           color.RGBA
+	rocket          color.RGBA
+	asteroidOutline color.RGBA
+	asteroidFill    color.RGBA
+	text            color.RGBA
+	highlight       color.RGBA
+}
+
+// Returns the default theme
+func classicTheme() *Theme {
+	return &Theme{
+		key:             defaultTheme,
+		manifest:        ThemeManifest{Name: "Classic", Author: "Go Asteroids", Description: "The original look and sound"},
+		background:      color.RGBA{0x00, 0x00, 0x00, 0xff},
+		stars:           color.RGBA{0xbb, 0xdd, 0xff, 0xff},
+		rocket:          color.RGBA{0xff, 0xff, 0xff, 0xff},
+		asteroidOutline: color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		asteroidFill:    color.RGBA{0x50, 0x48, 0x40, 0xff},
+		text:            color.RGBA{0xff, 0xff, 0xff, 0xff},
+		highlight:       color.RGBA{0x5d, 0xc9, 0xe2, 0xff},
+	}
+}
+
+// Returns the folder players put their own themes in
+func userThemesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileDir, themesDir), nil
+}
+
+// Finds every theme, the default first, then the built in ones and the player's own sorted by name.
+// A player's theme with the same folder name as a built in one replaces it.
+func findThemes() []*Theme {
+
+	found := make(map[string]*Theme)
+
+	builtIn, _ := fs.Sub(embeddedThemes, themesDir)
+	for _, t := range readThemes(builtIn, tr("console.built_in")) {
+		found[t.key] = t
+	}
+	if path, err := userThemesPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			for _, t := range readThemes(os.DirFS(path), path) {
+				found[t.key] = t
+			}
+		}
+	}
+
+	themes := []*Theme{classicTheme()}
+	var rest []*Theme
+	for key, t := range found {
+		if key != defaultTheme {
+			rest = append(rest, t)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].manifest.Name < rest[j].manifest.Name })
+	return append(themes, rest...)
+}
+
+// Reads the themes in each folder of a themes folder, skipping any without a readable manifest
+func readThemes(dir fs.FS, source string) []*Theme {
+
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.themes_unreadable", source, err))
+		return nil
+	}
+
+	var themes []*Theme
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		files, _ := fs.Sub(dir, e.Name())
+		t, err := readTheme(e.Name(), files, source)
+		if err != nil {
+			fmt.Printf("%s \n", tr("console.themes_skipping", e.Name(), source, err))
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes
+}
+
+// Reads one theme folder. Colours that can not be read keep the default ones.
+func readTheme(key string, files fs.FS, source string) (*Theme, error) {
+
+	data, err := fs.ReadFile(files, themeManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	t := classicTheme()
+	t.key, t.files, t.source = key, files, source
+	t.manifest = ThemeManifest{}
+	if err := json.Unmarshal(data, &t.manifest); err != nil {
+		return nil, errors.New(tr("console.theme_manifest_invalid", themeManifest, err))
+	}
+	if t.manifest.Name == "" {
+		t.manifest.Name = key
+	}
+
+	colours := map[string]*color.RGBA{
+		"background":      &t.background,
+		"stars":           &t.stars,
+		"rocket":          &t.rocket,
+		"asteroidOutline": &t.asteroidOutline,
+		"asteroidFill":    &t.asteroidFill,
+		"text":            &t.text,
+		"highlight":       &t.highlight,
+	}
+	for name, value := range t.manifest.Colours {
+		c, ok := colours[name]
+		if !ok {
+			fmt.Printf("%s \n", tr("console.theme_unknown_colour", key, name))
+			continue
+		}
+		if err := parseColour(value, c); err != nil {
+			fmt.Printf("%s \n", tr("console.theme_colour", key, name, err))
+		}
+	}
+	return t, nil
+}
+
+// Reads "#rrggbb" or "#rrggbbaa" into c, leaving c as it was if the colour can not be read
+func parseColour(s string, c *color.RGBA) error {
+	var r, g, b, a uint8 = 0, 0, 0, 0xff
+	var err error
+	switch len(s) {
+	case 7:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b)
+	case 9:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x%02x", &r, &g, &b, &a)
+	default:
+		return errors.New(tr("console.theme_colour_format", s))
+	}
+	if err != nil {
+		return errors.New(tr("console.theme_colour_format", s))
+	}
+	*c = color.RGBA{r, g, b, a}
+	return nil
+}
+
+// Returns the image source for the theme's art, or nil when it has none
+func (t *Theme) assetSource() *assetSource {
+	if t.files == nil {
+		return nil
+	}
+	return &assetSource{name: tr("console.theme_source", t.manifest.Name), files: t.files}
+}
+
+// Returns a sound from the theme as 16 bit stereo samples, or nil to use the built in one
+func (t *Theme) sound(name string) []byte {
+
+	file, ok := t.manifest.Sounds[name]
+	if !ok || t.files == nil {
+		return nil
+	}
+
+	f, err := t.files.Open(file)
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.theme_sound", t.key, name, err))
+		return nil
+	}
+	defer f.Close()
+
+	stream, err := wav.DecodeWithSampleRate(sampleRate, f)
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.theme_sound_invalid", t.key, name, err))
+		return nil
+	}
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		fmt.Printf("%s \n", tr("console.theme_sound", t.key, name, err))
+		return nil
+	}
+	return data
+}
+
+// Switches to a theme, reloading the images and sounds and saving the choice
+func (g *Game) useTheme(t *Theme) {
+
+	if t.source != "" {
+		fmt.Printf("%s \n", tr("console.theme_from", t.manifest.Name, t.source))
+	} else {
+		fmt.Printf("%s \n", tr("console.theme", t.manifest.Name))
+	}
+
+	g.theme = t
+	g.assets.theme = t.assetSource()
+	loadAssets(g)
+	g.audio.useTheme(t)
+
+	if g.profile != nil && g.profile.Theme != t.key {
+		g.profile.Theme = t.key
+		if err := g.profile.save(); err != nil {
+			fmt.Printf("%s \n", tr("console.error_saving_player_data", err))
+		}
+	}
+}
+
+// Chooses the saved theme when the game starts, the default if it is no longer there
+func (g *Game) loadTheme() {
+	g.themes = findThemes()
+	for _, t := range g.themes {
+		if g.profile != nil && t.key == g.profile.Theme {
+			g.useTheme(t)
+			return
+		}
+	}
+	g.useTheme(g.themes[0])
+}
+
+// Index of the theme in use in the list of themes
+func (g *Game) themeIndex() int {
+	for i, t := range g.themes {
+		if t == g.theme {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"image"
+	"io/fs"
+	"testing"
+)
+
+// Finds a built in theme by its folder name
+func builtInTheme(t *testing.T, key string) *Theme {
+	t.Helper()
+	dir, _ := fs.Sub(embeddedThemes, themesDir)
+	for _, theme := range readThemes(dir, "built in") {
+		if theme.key == key {
+			return theme
+		}
+	}
+	t.Fatalf("no built in theme %q", key)
+	return nil
+}
+
+func TestChalkboardThemeArt(t *testing.T) {
+	source := builtInTheme(t, "chalkboard").assetSource()
+	if source == nil {
+		t.Fatalf("chalkboard theme has no images")
+	}
+
+	// Every image replaces a built in one at the same size
+	for path, size := range placeholderSizes {
+		f, err := source.files.Open(path)
+		if err != nil {
+			t.Errorf("opening %s: %v", path, err)
+			continue
+		}
+		config, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("reading %s: %v", path, err)
+			continue
+		}
+		if config.Width != size[0] || config.Height != size[1] {
+			t.Errorf("%s is %dx%d, want %dx%d", path, config.Width, config.Height, size[0], size[1])
+		}
+	}
+}
+
+func TestChalkboardThemeSounds(t *testing.T) {
+	theme := builtInTheme(t, "chalkboard")
+
+	for _, name := range []string{"fire", "hit", "split", "damage", "menu"} {
+		if data := theme.sound(name); len(data) == 0 {
+			t.Errorf("%s sound did not load", name)
+		}
+	}
+	// The music is left to the built in loops
+	if data := theme.sound("playMusic"); data != nil {
+		t.Errorf("play music loaded from the theme")
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// How asteroids are drawn and the shape they collide with
+type AsteroidStyle int
+
+const (
+	// The asteroid images, colliding as rectangles
+	StyleSprite AsteroidStyle = 0
+
+	// Irregular polygons made from each asteroid's id, colliding as those polygons
+	StyleOutline AsteroidStyle = 1
+	StyleFilled  AsteroidStyle = 2
+
+	asteroidStyleCount = 3
+)
+
+func (s AsteroidStyle) String() string {
+	switch s {
+	case StyleOutline:
+		return tr("style.outline")
+	case StyleFilled:
+		return tr("style.filled")
+	}
+	return tr("style.sprites")
+}
+
+const (
+	// Corners on each asteroid polygon, and how far in from the edge of its box a corner can be
+	minCorners   = 8
+	extraCorners = 5
+	minRadius    = 0.6
+)
+
+// Returns the polygon of an asteroid around its centre, before it is rotated.
+// The shape is made from the asteroid's id, so every machine in a network game makes the same shape.
+func (a *Asteroid) polygon(w, h float64) []point {
+
+	if a.shape != nil {
+		return a.shape
+	}
+
+	r := rand.New(rand.NewSource(int64(a.id)))
+	n := minCorners + r.Intn(extraCorners)
+	a.shape = make([]point, n)
+	for i := range a.shape {
+		angle := 2 * math.Pi * (float64(i) + r.Float64()*0.5) / float64(n)
+		radius := minRadius + (1-minRadius)*r.Float64()
+		a.shape[i] = point{math.Cos(angle) * radius * w / 2, math.Sin(angle) * radius * h / 2}
+	}
+	return a.shape
+}
+
+// Returns the asteroid polygon where it is in the world, rotated as it is drawn
+func (a *Asteroid) worldPolygon(w, h float64) []point {
+	shape := a.polygon(w, h)
+	rotation := 2 * math.Pi * a.angle / maxAngle
+	sin, cos := math.Sin(rotation), math.Cos(rotation)
+	cx, cy := a.x+w/2, a.y+h/2
+
+	world := make([]point, len(shape))
+	for i, p := range shape {
+		world[i] = point{cx + p.x*cos - p.y*sin, cy + p.x*sin + p.y*cos}
+	}
+	return world
+}
+
+// Checks if an asteroid of the given size touches a rectangle, using the polygon when the asteroids are vectors
+func (g *Game) asteroidTouches(a *Asteroid, w, h float64, x, y, rw, rh float64) bool {
+	if g.asteroidStyle == StyleSprite {
+		return rectsOverlap(a.x, a.y, w, h, x, y, rw, rh)
+	}
+
+	// A rotated polygon stays within the square around its widest side
+	size := math.Max(w, h)
+	if !rectsOverlap(a.x+w/2-size/2, a.y+h/2-size/2, size, size, x, y, rw, rh) {
+		return false
+	}
+	return polygonTouchesRect(a.worldPolygon(w, h), x, y, rw, rh)
+}
+
+func rectsOverlap(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
+	return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
+}
+
+// Checks if a polygon and a rectangle overlap - a corner of one inside the other, or edges crossing
+func polygonTouchesRect(poly []point, x, y, w, h float64) bool {
+
+	rect := []point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+
+	for _, p := range poly {
+		if p.x >= x && p.x <= x+w && p.y >= y && p.y <= y+h {
+			return true
+		}
+	}
+	for _, p := range rect {
+		if pointInPolygon(p, poly) {
+			return true
+		}
+	}
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		for j := range rect {
+			if segmentsCross(a, b, rect[j], rect[(j+1)%4]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Ray casting - a point is inside if a line from it crosses the polygon's edges an odd number of times
+func pointInPolygon(p point, poly []point) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func segmentsCross(a, b, c, d point) bool {
+	cross := func(o, p, q point) float64 {
+		return (p.x-o.x)*(q.y-o.y) - (p.y-o.y)*(q.x-o.x)
+	}
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	return ((d1 > 0) != (d2 > 0)) && ((d3 > 0) != (d4 > 0))
+}
+
+// Draws asteroids as polygons through the camera, filled or outlined
+func (g *Game) drawVectorAsteroids(screen *ebiten.Image, list []*Asteroid, count int, w, h float64) {
+
+	camera := g.camera.geoM()
+	outline := g.theme.asteroidOutline
+	if g.config.HighContrast {
+		outline = highContrastOutline
+	}
+
+	for i := 0; i < count; i++ {
+		a := list[i]
+		poly := a.worldPolygon(w, h)
+		for j := range poly {
+			poly[j].x, poly[j].y = camera.Apply(poly[j].x, poly[j].y)
+		}
+
+		if g.asteroidStyle == StyleFilled {
+			cx, cy := camera.Apply(a.x+w/2, a.y+h/2)
+			fillPolygon(screen, poly, point{cx, cy}, g.theme.asteroidFill)
+		}
+		for j := range poly {
+			a, b := poly[j], poly[(j+1)%len(poly)]
+			ebitenutil.DrawLine(screen, a.x, a.y, b.x, b.y, outline)
+		}
+	}
+}
+
+// Fills a polygon whose every corner can be seen from its centre, as a fan of triangles
+func fillPolygon(screen *ebiten.Image, poly []point, centre point, c color.RGBA) {
+
+	r, g, b, a := float32(c.R)/0xff, float32(c.G)/0xff, float32(c.B)/0xff, float32(c.A)/0xff
+	vertices := []ebiten.Vertex{{DstX: float32(centre.x), DstY: float32(centre.y), ColorR: r, ColorG: g, ColorB: b, ColorA: a}}
+	var indices []uint16
+	for i, p := range poly {
+		vertices = append(vertices, ebiten.Vertex{DstX: float32(p.x), DstY: float32(p.y), ColorR: r, ColorG: g, ColorB: b, ColorA: a})
+		indices = append(indices, 0, uint16(i+1), uint16((i+1)%len(poly)+1))
+	}
+	screen.DrawTriangles(vertices, indices, whitePixel(), nil)
+}
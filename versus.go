@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Rounds a player must win to take the match, and the most rounds played
+	versusRoundsToWin = 2
+	versusMaxRounds   = 5
+
+	// Length of a round if the field is not cleared first
+	versusRoundTicks = 90 * ticksPerSecond
+
+	// Time a destroyed ship waits to respawn, then how long it can not be hit
+	versusRespawnTicks      = 3 * ticksPerSecond
+	versusInvulnerableTicks = 2 * ticksPerSecond
+
+	// Health lost when hit by the other player's rocket, and points for destroying their ship
+	versusHitDamage   = 25
+	eliminationPoints = 500
+)
+
+// Resets the round counters for a new versus match
+func (g *Game) initVersus() {
+	g.round = 1
+	g.roundTicks = 0
+	g.roundWinner = 0
+	for _, p := range g.players {
+		p.roundWins = 0
+		p.eliminations = 0
+		p.roundStartScore = 0
+	}
+}
+
+// Per tick versus rules - respawning destroyed ships and ending rounds
+func (g *Game) updateVersus() {
+
+	g.roundTicks++
+
+	for _, p := range g.players {
+		if p.invulnerable > 0 {
+			p.invulnerable--
+		}
+
+		if p.alive() {
+			continue
+		}
+		if p.respawnTicks == 0 {
+			// Ship has just been destroyed
+			p.respawnTicks = versusRespawnTicks
+			p.resetRocket()
+			continue
+		}
+		p.respawnTicks--
+		if p.respawnTicks == 0 {
+			p.respawn()
+		}
+	}
+
+	if g.roundTicks >= versusRoundTicks || (AsteroidsInGame == 0 && miniAsteroidsInGame == 0) {
+		g.endRound()
+	}
+}
+
+// Brings a destroyed ship back at its starting position, briefly invulnerable
+func (p *Player) respawn() {
+	p.health = playerMaxHealth
+	p.shipXPos = p.spawnXPos
+	p.shipYPos = float64(worldHeight) - float64(shipHeight*2)
+	p.resetRocket()
+	p.respawnTicks = 0
+	p.invulnerable = versusInvulnerableTicks
+	fmt.Printf("%s \n", tr("console.respawned", p.id))
+}
+
+// Awards points when a player destroys the other player's ship
+func (g *Game) eliminated(shooter, target *Player) {
+	if g.gameType != TypeVersus {
+		return
+	}
+	shooter.score += eliminationPoints
+	shooter.eliminations++
+	fmt.Printf("%s \n", tr("console.eliminated", shooter.id, target.id))
+}
+
+// Returns the points a player has scored this round
+func (p *Player) roundScore() int {
+	return p.score - p.roundStartScore
+}
+
+// Ends the round, giving it to the player with the most points, then starts the next round or ends the match
+func (g *Game) endRound() {
+
+	g.roundWinner = 0
+	p1, p2 := g.players[0], g.players[1]
+	if p1.roundScore() > p2.roundScore() {
+		g.roundWinner = p1.id
+		p1.roundWins++
+	} else if p2.roundScore() > p1.roundScore() {
+		g.roundWinner = p2.id
+		p2.roundWins++
+	}
+	fmt.Printf("%s \n", tr("console.round_over", g.round, g.roundWinner))
+
+	if p1.roundWins >= versusRoundsToWin || p2.roundWins >= versusRoundsToWin || g.round >= versusMaxRounds {
+		g.finishGame(ModeResult)
+		return
+	}
+
+	// Start the next round on a fresh field
+	g.round++
+	g.roundTicks = 0
+	for _, p := range g.players {
+		p.roundStartScore = p.score
+		p.respawn()
+	}
+	resetAsteroids(g)
+}
+
+// Returns the id of the player who won the match, or 0 for a draw
+func (g *Game) matchWinner() int {
+	p1, p2 := g.players[0], g.players[1]
+	switch {
+	case p1.roundWins != p2.roundWins:
+		if p1.roundWins > p2.roundWins {
+			return p1.id
+		}
+		return p2.id
+	case p1.score != p2.score:
+		if p1.score > p2.score {
+			return p1.id
+		}
+		return p2.id
+	}
+	return 0
+}
+
+// Checks if the match was won by a player on this machine
+func (g *Game) localWinner() bool {
+	winner := g.matchWinner()
+	for _, p := range g.players {
+		if p.id == winner && !p.remote {
+			return true
+		}
+	}
+	return false
+}
+
+// Draws the round, time left and respawn timers during a versus match
+func (g *Game) drawVersusHUD(screen *ebiten.Image) {
+
+	timeLeft := (versusRoundTicks - g.roundTicks) / ticksPerSecond
+	g.print(screen, tr("hud.round", g.round, timeLeft), fromRight(g.hudRight()), 40)
+	g.print(screen, tr("hud.rounds_won", g.players[0].roundWins, g.players[1].roundWins), fromRight(g.hudRight()), 60)
+
+	if g.round > 1 && g.roundTicks < 2*ticksPerSecond {
+		last := tr("versus.round_draw")
+		if g.roundWinner != 0 {
+			last = tr("versus.round_won", g.roundWinner, g.round-1)
+		}
+		drawCentredText(screen, last, g.fonts.body, 280, g.theme.text)
+	}
+
+	for i, p := range g.players {
+		if !p.alive() {
+			x := (i%2)*(screenWidth-240) + 10
+			y := fromBottom(80) - (i/2)*70
+			g.print(screen, tr("hud.respawning", p.respawnTicks/ticksPerSecond+1), x, y)
+		}
+	}
+}
+
+// Draws the match results screen
+func (g *Game) drawVersusResults(screen *ebiten.Image) {
+
+	g.drawLogo(screen)
+
+	winner := tr("versus.match_draw")
+	if w := g.matchWinner(); w != 0 {
+		winner = tr("versus.match_won", w)
+	}
+	drawCentredText(screen, tr("versus.title"), g.fonts.heading, 305, g.theme.text)
+	drawCentredText(screen, winner, g.fonts.body, 340, g.theme.highlight)
+
+	for i, p := range g.players {
+		line := tr("versus.player_result", p.id, p.roundWins, p.score, p.eliminations)
+		drawCentredText(screen, line, g.fonts.small, 375+i*20, g.theme.text)
+	}
+
+	g.menus[ModeResult].draw(g, screen, 440)
+}